@@ -0,0 +1,170 @@
+package astutil
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// FixUnused neutralizes the imports and local variables of the given file which are no longer used, e.g. because a mutation removed their only use, so that the file still compiles.
+// Unused imports become blank imports which keeps the initialization of their packages. A "_ = v" statement is added after the declaration of every unused variable.
+// The returned function reverts all fixes. The number of fixes is returned too.
+func FixUnused(info *types.Info, file *ast.File) (reset func(), fixes int) {
+	reads := map[types.Object]int{}
+	// Identifiers created by mutations are not known to the type information, so they can only be matched by name
+	readNames := map[string]struct{}{}
+	written := map[*ast.Ident]struct{}{}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.AssignStmt:
+			// Variables which are only assigned to are not used
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						written[id] = struct{}{}
+					}
+				}
+			}
+		case *ast.IncDecStmt:
+			if id, ok := n.X.(*ast.Ident); ok {
+				written[id] = struct{}{}
+			}
+		case *ast.Ident:
+			if _, ok := written[n]; ok {
+				return true
+			}
+
+			if obj, ok := info.Uses[n]; ok {
+				reads[obj]++
+			} else if _, ok := info.Defs[n]; !ok {
+				readNames[n.Name] = struct{}{}
+			}
+		}
+
+		return true
+	})
+
+	unused := func(obj types.Object) bool {
+		if obj == nil || obj.Name() == "_" || reads[obj] > 0 {
+			return false
+		}
+
+		_, ok := readNames[obj.Name()]
+
+		return !ok
+	}
+
+	var resets []func()
+
+	for _, spec := range file.Imports {
+		var obj types.Object
+		if spec.Name == nil {
+			obj = info.Implicits[spec]
+		} else if spec.Name.Name != "_" && spec.Name.Name != "." {
+			obj = info.Defs[spec.Name]
+		}
+
+		if !unused(obj) {
+			continue
+		}
+
+		s := spec
+		old := s.Name
+		s.Name = ast.NewIdent("_")
+		fixes++
+		resets = append(resets, func() {
+			s.Name = old
+		})
+	}
+
+	// Statements are added after the declarations of unused variables, or at the start of the body for range variables
+	inserts := map[*[]ast.Stmt]map[int][]ast.Stmt{}
+	insert := func(list *[]ast.Stmt, after int, id *ast.Ident) {
+		if !unused(info.Defs[id]) {
+			return
+		}
+
+		if inserts[list] == nil {
+			inserts[list] = map[int][]ast.Stmt{}
+		}
+		inserts[list][after] = append(inserts[list][after], &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("_")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ast.NewIdent(id.Name)},
+		})
+		fixes++
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		var list *[]ast.Stmt
+
+		switch n := node.(type) {
+		case *ast.BlockStmt:
+			list = &n.List
+		case *ast.CaseClause:
+			list = &n.Body
+		case *ast.CommClause:
+			list = &n.Body
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				for _, e := range []ast.Expr{n.Key, n.Value} {
+					if id, ok := e.(*ast.Ident); ok {
+						insert(&n.Body.List, -1, id)
+					}
+				}
+			}
+		}
+
+		if list == nil {
+			return true
+		}
+
+		for i, stmt := range *list {
+			switch s := stmt.(type) {
+			case *ast.AssignStmt:
+				if s.Tok == token.DEFINE {
+					for _, lhs := range s.Lhs {
+						if id, ok := lhs.(*ast.Ident); ok {
+							insert(list, i, id)
+						}
+					}
+				}
+			case *ast.DeclStmt:
+				if d, ok := s.Decl.(*ast.GenDecl); ok && d.Tok == token.VAR {
+					for _, spec := range d.Specs {
+						for _, id := range spec.(*ast.ValueSpec).Names {
+							insert(list, i, id)
+						}
+					}
+				}
+			}
+		}
+
+		return true
+	})
+
+	for list, stmts := range inserts {
+		l := list
+		old := *l
+
+		// The original list must not be modified since mutations hold on to it
+		fixed := make([]ast.Stmt, 0, len(old)+len(stmts))
+		fixed = append(fixed, stmts[-1]...)
+		for i, stmt := range old {
+			fixed = append(fixed, stmt)
+			fixed = append(fixed, stmts[i]...)
+		}
+
+		*l = fixed
+		resets = append(resets, func() {
+			*l = old
+		})
+	}
+
+	return func() {
+		for i := len(resets) - 1; i >= 0; i-- {
+			resets[i]()
+		}
+	}, fixes
+}
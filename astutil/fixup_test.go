@@ -0,0 +1,92 @@
+package astutil
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixUnused(t *testing.T) {
+	original := `package fix
+
+import (
+	"fmt"
+	"strings"
+)
+
+func fix(a []string) string {
+	s := strings.Join(a, ",")
+	n := 0
+	for i, v := range a {
+		n += i
+		fmt.Println(v)
+	}
+
+	return s + fmt.Sprint(n)
+}
+`
+
+	typeCheck := func(src string) (*token.FileSet, *ast.File, *types.Info, error) {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "fix.go", src, parser.ParseComments)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		info := &types.Info{
+			Defs:      map[*ast.Ident]types.Object{},
+			Implicits: map[ast.Node]types.Object{},
+			Uses:      map[*ast.Ident]types.Object{},
+		}
+		conf := types.Config{
+			Importer: importer.ForCompiler(fset, "source", nil),
+		}
+		_, err = conf.Check("fix", fset, []*ast.File{file}, info)
+
+		return fset, file, info, err
+	}
+	printFile := func(fset *token.FileSet, file *ast.File) string {
+		var buf bytes.Buffer
+		assert.Nil(t, printer.Fprint(&buf, fset, file))
+
+		return buf.String()
+	}
+
+	fset, file, info, err := typeCheck(original)
+	assert.Nil(t, err)
+
+	// Nothing is unused in the original file
+	reset, fixes := FixUnused(info, file)
+	assert.Equal(t, 0, fixes)
+	reset()
+
+	// Remove the body of the loop and the use of n and fmt in the return statement
+	fn := file.Decls[1].(*ast.FuncDecl)
+	loop := fn.Body.List[2].(*ast.RangeStmt)
+	oldBody := loop.Body.List
+	oldReturn := fn.Body.List[3]
+	loop.Body.List = nil
+	fn.Body.List[3] = &ast.ReturnStmt{
+		Results: []ast.Expr{ast.NewIdent("s")},
+	}
+
+	reset, fixes = FixUnused(info, file)
+	// i, v, n and fmt are unused
+	assert.Equal(t, 4, fixes)
+
+	_, _, _, err = typeCheck(printFile(fset, file))
+	assert.Nil(t, err)
+
+	reset()
+	loop.Body.List = oldBody
+	fn.Body.List[3] = oldReturn
+
+	assert.Equal(t, original, printFile(fset, file))
+}
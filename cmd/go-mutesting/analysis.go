@@ -0,0 +1,491 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+const (
+	// statusProbablyEquivalent is the status of mutations which the static analysis found to not change the behavior of the program.
+	statusProbablyEquivalent = "PROBABLY_EQUIVALENT"
+	// statusUnreachable is the status of mutations which the static analysis found to be never executed.
+	statusUnreachable = "UNREACHABLE"
+)
+
+// finding holds why the static analysis found that a mutation cannot be killed.
+type finding struct {
+	status string
+	reason string
+}
+
+// staticAnalysis holds the SSA form of the mutated packages.
+type staticAnalysis struct {
+	fset *token.FileSet
+	// packages maps the path of every mutated package to its SSA package including its tests.
+	packages map[string]*ssa.Package
+	// testPackages maps the path of every mutated package to its external test package.
+	testPackages map[string]*ssa.Package
+	// functions holds the functions of every mutated package including anonymous functions.
+	functions map[*ssa.Package][]*ssa.Function
+	// syntax maps the absolute paths of the files of the mutated packages to their ASTs.
+	syntax map[string]*ast.File
+	// pure holds every function with a body which neither has side effects nor can panic.
+	pure map[*ssa.Function]bool
+	// reachable holds the normalized names of all functions which are reachable from the entry points of their packages.
+	reachable map[string]bool
+}
+
+// analyzeMutants marks the given mutants which the static analysis of their packages finds to be probably equivalent or unreachable.
+func analyzeMutants(opts *options, mutants []*mutant) error {
+	var pkgPaths []string
+	seen := map[string]struct{}{}
+
+	for _, m := range mutants {
+		if _, ok := seen[m.pkgPath]; !ok {
+			seen[m.pkgPath] = struct{}{}
+			pkgPaths = append(pkgPaths, m.pkgPath)
+		}
+	}
+
+	if len(pkgPaths) == 0 {
+		return nil
+	}
+
+	a, err := newStaticAnalysis(opts, pkgPaths)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range mutants {
+		if m.compileError != nil {
+			continue
+		}
+
+		if m.finding = a.analyze(m); m.finding != nil {
+			debug(opts, "%q is %s: %s", m.mutationFile, m.finding.status, m.finding.reason)
+		}
+	}
+
+	return nil
+}
+
+// newStaticAnalysis loads the given packages with their tests and builds their SSA form.
+func newStaticAnalysis(opts *options, pkgPaths []string) (*staticAnalysis, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedTypesSizes,
+		Tests: true,
+	}, pkgPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages %s: %v", strings.Join(pkgPaths, ", "), err)
+	}
+
+	// Locals are kept in memory so that stores to them can be found
+	prog, ssaPkgs := ssautil.Packages(pkgs, ssa.NaiveForm)
+	prog.Build()
+
+	a := &staticAnalysis{
+		fset:         prog.Fset,
+		packages:     map[string]*ssa.Package{},
+		testPackages: map[string]*ssa.Package{},
+		functions:    map[*ssa.Package][]*ssa.Function{},
+		syntax:       map[string]*ast.File{},
+		pure:         map[*ssa.Function]bool{},
+		reachable:    map[string]bool{},
+	}
+
+	for i, p := range pkgs {
+		if len(p.Errors) > 0 {
+			verbose(opts, "Could not analyze package %q statically: %v", p.ID, p.Errors[0])
+
+			continue
+		} else if ssaPkgs[i] == nil || p.ID == p.PkgPath+".test" {
+			// The generated main package of the tests is not analyzed
+			continue
+		}
+
+		withTests := strings.Contains(p.ID, " ")
+
+		if strings.HasSuffix(p.PkgPath, "_test") && withTests {
+			a.testPackages[strings.TrimSuffix(p.PkgPath, "_test")] = ssaPkgs[i]
+		} else if _, ok := a.packages[p.PkgPath]; !ok || withTests {
+			// The package with its tests supersedes the package without its tests
+			a.packages[p.PkgPath] = ssaPkgs[i]
+
+			for _, f := range p.Syntax {
+				a.syntax[absFile(prog.Fset.File(f.Pos()).Name())] = f
+			}
+		}
+	}
+
+	a.analyzeFunctions(prog)
+
+	return a, nil
+}
+
+// analyzeFunctions determines which functions of the given program are pure and which are reachable.
+func (a *staticAnalysis) analyzeFunctions(prog *ssa.Program) {
+	functions := ssautil.AllFunctions(prog)
+
+	byName := map[string][]*ssa.Function{}
+	for f := range functions {
+		byName[functionName(f)] = append(byName[functionName(f)], f)
+
+		if f.Pkg != nil && f.Synthetic == "" {
+			a.functions[f.Pkg] = append(a.functions[f.Pkg], f)
+		}
+
+		if len(f.Blocks) > 0 {
+			a.pure[f] = true
+		}
+	}
+
+	// A function is pure until one of its instructions, including calls of impure functions, is not
+	for changed := true; changed; {
+		changed = false
+
+		for f := range a.pure {
+			if !a.pure[f] {
+				continue
+			}
+
+			for _, b := range f.Blocks {
+				for _, instr := range b.Instrs {
+					if !a.pureInstruction(instr) {
+						a.pure[f] = false
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	var queue []string
+	reach := func(name string) {
+		if !a.reachable[name] {
+			a.reachable[name] = true
+			queue = append(queue, name)
+		}
+	}
+
+	for pkgPath, p := range a.packages {
+		for _, f := range a.functions[p] {
+			if f.Parent() == nil && entryPoint(prog.Fset, f) {
+				reach(functionName(f))
+			}
+		}
+
+		// Every function of the external tests can use the package
+		for _, f := range a.functions[a.testPackages[pkgPath]] {
+			reach(functionName(f))
+		}
+	}
+
+	// Every function which is called or used as value by a reachable function is reachable too
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		for _, f := range byName[name] {
+			for _, anon := range f.AnonFuncs {
+				reach(functionName(anon))
+			}
+
+			for _, b := range f.Blocks {
+				for _, instr := range b.Instrs {
+					for _, op := range instr.Operands(nil) {
+						if g, ok := (*op).(*ssa.Function); ok {
+							reach(functionName(g))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// entryPoint returns true if the given function can be called from outside of its package, by the runtime or by tests.
+// Methods are always entry points since they could be called through interfaces.
+func entryPoint(fset *token.FileSet, f *ssa.Function) bool {
+	return f.Signature.Recv() != nil || ast.IsExported(f.Name()) || strings.HasPrefix(f.Name(), "init") || f.Name() == "main" || strings.HasSuffix(fset.Position(f.Pos()).Filename, "_test.go")
+}
+
+// functionName returns the name of the given function without type arguments, so that instances of generic functions have the same name as their generic function.
+func functionName(f *ssa.Function) string {
+	var name strings.Builder
+
+	depth := 0
+	for _, r := range f.String() {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			name.WriteRune(r)
+		}
+	}
+
+	return name.String()
+}
+
+// pureInstruction returns true if the given instruction neither has side effects outside of its function nor can panic.
+func (a *staticAnalysis) pureInstruction(instr ssa.Instruction) bool {
+	switch i := instr.(type) {
+	case *ssa.Alloc, *ssa.ChangeInterface, *ssa.ChangeType, *ssa.DebugRef, *ssa.Extract, *ssa.Field, *ssa.If, *ssa.Jump, *ssa.MakeClosure, *ssa.MakeInterface, *ssa.MakeMap, *ssa.Next, *ssa.Phi, *ssa.Range, *ssa.Return, *ssa.RunDefers:
+		return true
+	case *ssa.BinOp:
+		c, _ := i.Y.(*ssa.Const)
+
+		switch i.Op {
+		case token.QUO, token.REM:
+			// Integer divisions by zero panic
+			return !isInteger(i.Y.Type()) || (c != nil && c.Value != nil && constant.Sign(c.Value) != 0)
+		case token.SHL, token.SHR:
+			// Shifts by negative counts panic
+			return !isSigned(i.Y.Type()) || (c != nil && c.Value != nil && constant.Sign(c.Value) >= 0)
+		}
+
+		return true
+	case *ssa.Convert:
+		// Conversions of slices to arrays panic if the slice is too short
+		_, slice := i.X.Type().Underlying().(*types.Slice)
+
+		return !slice
+	case *ssa.UnOp:
+		switch i.Op {
+		case token.ARROW:
+			return false
+		case token.MUL:
+			// Only loads of locals and globals cannot panic
+			_, global := i.X.(*ssa.Global)
+
+			return global || localAddress(i.X)
+		}
+
+		return true
+	case *ssa.FieldAddr:
+		return localAddress(i.X)
+	case *ssa.Store:
+		return localAddress(i.Addr)
+	case *ssa.Lookup:
+		// Lookups in maps cannot panic in contrast to indexing strings
+		_, ok := i.X.Type().Underlying().(*types.Map)
+
+		return ok
+	case *ssa.Call:
+		if i.Call.IsInvoke() {
+			return false
+		}
+
+		switch f := i.Call.Value.(type) {
+		case *ssa.Builtin:
+			switch f.Name() {
+			case "len", "cap", "complex", "real", "imag", "min", "max", "ssa:deferstack":
+				return true
+			}
+		case *ssa.Function:
+			return a.pure[f]
+		}
+	}
+
+	return false
+}
+
+// localAddress returns true if the given address points into a local variable of its function.
+func localAddress(v ssa.Value) bool {
+	switch v := v.(type) {
+	case *ssa.Alloc:
+		return true
+	case *ssa.FieldAddr:
+		return localAddress(v.X)
+	}
+
+	return false
+}
+
+func isInteger(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+func isSigned(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+
+	return ok && b.Info()&types.IsUnsigned == 0
+}
+
+// analyze returns why the given mutant cannot be killed, or nil if the static analysis does not know.
+func (a *staticAnalysis) analyze(m *mutant) *finding {
+	file, ok := a.syntax[absFile(m.file)]
+	if !ok {
+		return nil
+	}
+	tokenFile := a.fset.File(file.Pos())
+	if m.end.Offset > tokenFile.Size() {
+		return nil
+	}
+	pos, end := tokenFile.Pos(m.start.Offset), tokenFile.Pos(m.end.Offset)
+
+	// The innermost function which contains the mutation
+	var fn *ssa.Function
+	for _, f := range a.functions[a.packages[m.pkgPath]] {
+		syntax := f.Syntax()
+		if syntax == nil || syntax.Pos() > pos || syntax.End() < end {
+			continue
+		}
+
+		if fn == nil || (fn.Syntax().Pos() <= syntax.Pos() && syntax.End() <= fn.Syntax().End()) {
+			fn = f
+		}
+	}
+	if fn == nil {
+		return nil
+	}
+
+	outermost := fn
+	for outermost.Parent() != nil {
+		outermost = outermost.Parent()
+	}
+	if !a.reachable[functionName(outermost)] {
+		return &finding{
+			status: statusUnreachable,
+			reason: fmt.Sprintf("%s is not reachable from any exported function, method, init function or test", outermost.Name()),
+		}
+	}
+
+	// The innermost statement which contains the mutation
+	var stmt ast.Stmt
+	ast.Inspect(fn.Syntax(), func(n ast.Node) bool {
+		if n == nil || n.Pos() > pos || n.End() < end {
+			return false
+		}
+
+		if s, ok := n.(ast.Stmt); ok {
+			stmt = s
+		}
+
+		return true
+	})
+
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok || !a.pureStatement(fn, s) {
+			return nil
+		}
+
+		return &finding{
+			status: statusProbablyEquivalent,
+			reason: fmt.Sprintf("%s has no side effects and its result is not used", types.ExprString(call.Fun)),
+		}
+	case *ast.AssignStmt:
+		var names []string
+		for _, lhs := range s.Lhs {
+			id, ok := lhs.(*ast.Ident)
+			if !ok || (id.Name != "_" && !deadStore(fn, id)) {
+				return nil
+			}
+
+			names = append(names, id.Name)
+		}
+
+		if !a.pureStatement(fn, s) {
+			return nil
+		}
+
+		return &finding{
+			status: statusProbablyEquivalent,
+			reason: fmt.Sprintf("the value assigned to %s is never used", strings.Join(names, ", ")),
+		}
+	}
+
+	return nil
+}
+
+// pureStatement returns true if all instructions of the given function which belong to the given statement are pure.
+func (a *staticAnalysis) pureStatement(fn *ssa.Function, stmt ast.Stmt) bool {
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if instr.Pos() >= stmt.Pos() && instr.Pos() < stmt.End() && !a.pureInstruction(instr) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// deadStore returns true if the value which is stored into the local variable of the given identifier is never loaded.
+func deadStore(fn *ssa.Function, id *ast.Ident) bool {
+	for _, b := range fn.Blocks {
+		for i, instr := range b.Instrs {
+			store, ok := instr.(*ssa.Store)
+			if !ok || store.Pos() != id.Pos() {
+				continue
+			}
+
+			alloc, ok := store.Addr.(*ssa.Alloc)
+			if !ok {
+				return false
+			}
+
+			// Variables whose address is used otherwise, e.g. by closures, could be loaded anywhere
+			for _, ref := range *alloc.Referrers() {
+				switch r := ref.(type) {
+				case *ssa.Store:
+					if r.Addr != alloc {
+						return false
+					}
+				case *ssa.UnOp:
+					if r.Op != token.MUL {
+						return false
+					}
+				case *ssa.DebugRef:
+				default:
+					return false
+				}
+			}
+
+			return !loaded(alloc, b, i+1, map[*ssa.BasicBlock]bool{})
+		}
+	}
+
+	return false
+}
+
+// loaded returns true if the given variable is loaded starting with the given instruction of the given block before it is stored again.
+func loaded(alloc *ssa.Alloc, b *ssa.BasicBlock, start int, visited map[*ssa.BasicBlock]bool) bool {
+	for _, instr := range b.Instrs[start:] {
+		switch i := instr.(type) {
+		case *ssa.UnOp:
+			if i.Op == token.MUL && i.X == alloc {
+				return true
+			}
+		case *ssa.Store:
+			if i.Addr == alloc {
+				return false
+			}
+		}
+	}
+
+	for _, succ := range b.Succs {
+		if !visited[succ] {
+			visited[succ] = true
+
+			if loaded(alloc, succ, 0, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deadSource holds a package with a dead store, a call of a side-effect free function and an unreachable function.
+const deadSource = `package dead
+
+func double(a int) int {
+	return a * 2
+}
+
+// Sum returns the sum of the given numbers if it is positive.
+func Sum(a []int) int {
+	positive := len(a) > 0
+	s := 0
+	for _, v := range a {
+		double(v)
+		s += v
+	}
+	positive = s > 0
+	if !positive {
+		return 0
+	}
+
+	return s
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+`
+
+const deadTestSource = `package dead
+
+import "testing"
+
+func TestSum(t *testing.T) {
+	if Sum([]int{1, 2}) != 3 || Sum([]int{-1}) != 0 {
+		t.Fail()
+	}
+}
+`
+
+func TestStaticAnalysis(t *testing.T) {
+	dir, removeModule := writeModule(t, "dead", deadSource, deadTestSource)
+	defer removeModule()
+
+	saveCwd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer func() {
+		assert.Nil(t, os.Chdir(saveCwd))
+	}()
+
+	a, err := newStaticAnalysis(&options{}, []string{"dead"})
+	assert.Nil(t, err)
+
+	mutantOf := func(code string) *mutant {
+		offset := strings.Index(deadSource, code)
+		assert.True(t, offset >= 0, code)
+
+		return &mutant{
+			file:    filepath.Join(dir, "dead.go"),
+			pkgPath: "dead",
+			start:   token.Position{Offset: offset},
+			end:     token.Position{Offset: offset + len(code)},
+		}
+	}
+
+	for code, expected := range map[string]*finding{
+		"len(a) > 0": {
+			status: statusProbablyEquivalent,
+			reason: "the value assigned to positive is never used",
+		},
+		"double(v)": {
+			status: statusProbablyEquivalent,
+			reason: "double has no side effects and its result is not used",
+		},
+		"a < 0": {
+			status: statusUnreachable,
+			reason: "abs is not reachable from any exported function, method, init function or test",
+		},
+		"s > 0":  nil,
+		"s += v": nil,
+		"a * 2":  nil,
+	} {
+		assert.Equal(t, expected, a.analyze(mutantOf(code)), code)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/zimmski/osutil"
+)
+
+// runBaselines executes the tests of the packages of all given mutants without any mutation and sets the timeout of every mutant according to the test duration of its package.
+// If coverage filtering is enabled, all mutants which are not covered by the tests of their package are marked. If only covering tests should be executed, the tests of every mutant are selected.
+func runBaselines(opts *options, j *journal, tmpDir string, mutants []*mutant) error {
+	durations := map[string]time.Duration{}
+
+	for _, m := range mutants {
+		if _, ok := durations[m.pkgPath]; ok {
+			continue
+		}
+
+		copyFile := filepath.Join(tmpDir, fmt.Sprintf("baseline.%d.go", len(durations)))
+
+		var profileFile string
+		if opts.Filter.Coverage {
+			profileFile = filepath.Join(tmpDir, fmt.Sprintf("coverage.%d.out", len(durations)))
+
+			// The instrumentation of the coverage would inflate the duration of the tests, so they are timed in another run
+			if _, err := runBaseline(opts, j, m.pkgPath, m.file, copyFile, profileFile); err != nil {
+				return err
+			}
+		}
+
+		duration, err := runBaseline(opts, j, m.pkgPath, m.file, copyFile, "")
+		if err != nil {
+			return err
+		}
+
+		durations[m.pkgPath] = duration
+
+		if profileFile != "" {
+			if err := markNotCovered(profileFile, m.pkgPath, mutants); err != nil {
+				return fmt.Errorf("could not read coverage profile of package %q: %v", m.pkgPath, err)
+			}
+		}
+
+		if opts.Test.Covering {
+			if err := selectTests(opts, m.pkgPath, filepath.Dir(m.file), mutants); err != nil {
+				return fmt.Errorf("could not map the tests of package %q: %v", m.pkgPath, err)
+			}
+		}
+
+		verbose(opts, "Tests of package %q took %s without any mutation, mutations time out after %s", m.pkgPath, duration, mutantTimeout(opts, duration))
+	}
+
+	for _, m := range mutants {
+		m.timeout = mutantTimeout(opts, durations[m.pkgPath])
+	}
+
+	return nil
+}
+
+// runBaseline executes the tests of the given package without any mutation and returns their duration.
+// Since every mutation has to compile its package and link the test binary, the given file of the package is replaced by the given unique but otherwise unchanged copy so that the duration includes the build of the package too.
+// If a profile file is given, the coverage of the package is written to it.
+func runBaseline(opts *options, j *journal, pkgPath string, file string, copyFile string, profileFile string) (duration time.Duration, err error) {
+	pkgName := pkgPath
+	if opts.Test.Recursive {
+		pkgName += "/..."
+	}
+
+	// Cached test results would not tell how long the tests take
+	args := []string{"test", "-count=1"}
+
+	if err := writeBaselineCopy(file, copyFile); err != nil {
+		return 0, fmt.Errorf("could not copy %q: %v", file, err)
+	}
+
+	if opts.Exec.InPlace {
+		// The file is still pristine since no mutation is executed yet
+		if err := j.Add(file, file, copyFile); err != nil {
+			return 0, err
+		}
+
+		defer func() {
+			_ = os.Rename(file+".tmp", file)
+
+			if rerr := j.Restore(copyFile); rerr != nil && err == nil {
+				err = rerr
+			}
+		}()
+
+		if err := os.Rename(file, file+".tmp"); err != nil {
+			return 0, err
+		}
+		if err := osutil.CopyFile(copyFile, file); err != nil {
+			return 0, err
+		}
+	} else {
+		overlayFile, err := writeOverlayFile(file, copyFile)
+		if err != nil {
+			return 0, fmt.Errorf("could not write the overlay of package %q: %v", pkgPath, err)
+		}
+		args = append(args, "-overlay", overlayFile)
+	}
+
+	if profileFile != "" {
+		// The coverage of the package itself is needed, even if it is tested recursively
+		args = append(args, "-coverpkg", pkgPath, "-coverprofile", profileFile)
+	}
+
+	var out bytes.Buffer
+
+	cmd := exec.Command("go", append(args, pkgName)...)
+	cmd.Stderr = &out
+	cmd.Stdout = &out
+
+	start := time.Now()
+
+	exitCode, timedOut, err := runCommand(cmd, time.Duration(opts.Exec.Timeout)*time.Second)
+	if err != nil {
+		return 0, err
+	}
+
+	duration = time.Since(start)
+
+	if timedOut {
+		return 0, fmt.Errorf("tests of package %q exceeded the timeout without any mutation", pkgName)
+	} else if exitCode != 0 {
+		return 0, fmt.Errorf("tests of package %q fail without any mutation:\n%s", pkgName, out.Bytes())
+	}
+
+	return duration, nil
+}
+
+// writeBaselineCopy writes a copy of the given file with a unique trailing comment.
+// The comment changes neither the code nor its positions but keeps the build cache from providing the already compiled package.
+func writeBaselineCopy(file string, copyFile string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	data = append(data, fmt.Sprintf("\n// go-mutesting baseline %d\n", time.Now().UnixNano())...)
+
+	return ioutil.WriteFile(copyFile, data, 0666)
+}
+
+// mutantTimeout returns the timeout of a mutation for the given test duration of its package.
+func mutantTimeout(opts *options, duration time.Duration) time.Duration {
+	if opts.Exec.Timeout > 0 {
+		return time.Duration(opts.Exec.Timeout) * time.Second
+	}
+
+	timeout := time.Duration(float64(duration)*opts.Exec.TimeoutFactor) + time.Duration(opts.Exec.TimeoutConstant)*time.Second
+
+	return timeout.Round(time.Millisecond)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutantTimeout(t *testing.T) {
+	opts := &options{}
+	opts.Exec.TimeoutFactor = 3
+	opts.Exec.TimeoutConstant = 5
+
+	assert.Equal(t, 5*time.Second, mutantTimeout(opts, 0))
+	assert.Equal(t, 11*time.Second, mutantTimeout(opts, 2*time.Second))
+	assert.Equal(t, 5*time.Second+3*time.Millisecond, mutantTimeout(opts, time.Millisecond+123*time.Microsecond))
+
+	opts.Exec.TimeoutFactor = 0
+	opts.Exec.TimeoutConstant = 0
+	assert.Equal(t, time.Duration(0), mutantTimeout(opts, 2*time.Second))
+
+	// A fixed timeout ignores the test duration
+	opts.Exec.TimeoutFactor = 3
+	opts.Exec.TimeoutConstant = 5
+	opts.Exec.Timeout = 10
+	assert.Equal(t, 10*time.Second, mutantTimeout(opts, time.Minute))
+}
+
+func TestWriteBaselineCopy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mutesting-baseline-")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}()
+
+	file := filepath.Join(dir, "file.go")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("package file\n"), 0644))
+
+	copyFile := filepath.Join(dir, "baseline.0.go")
+	assert.Nil(t, writeBaselineCopy(file, copyFile))
+
+	data, err := ioutil.ReadFile(copyFile)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "package file\n\n// go-mutesting baseline "))
+
+	// Every copy is unique so that the package is never taken from the build cache
+	assert.Nil(t, writeBaselineCopy(file, copyFile))
+	again, err := ioutil.ReadFile(copyFile)
+	assert.Nil(t, err)
+	assert.NotEqual(t, string(data), string(again))
+}
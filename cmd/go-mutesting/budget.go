@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gitLineTimes returns the time of the last change of every line of the given file according to git, not yet committed lines are changed now.
+func gitLineTimes(file string) (map[int]int64, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", filepath.Base(file))
+	cmd.Dir = filepath.Dir(file)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not blame %q: %v\n%s", file, err, stderr.Bytes())
+	}
+
+	return parseBlame(bytes.NewReader(out))
+}
+
+// parseBlame parses the given output of "git blame --line-porcelain" and returns the committer time of every line.
+func parseBlame(r io.Reader) (map[int]int64, error) {
+	times := map[int]int64{}
+
+	var line int
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for s.Scan() {
+		text := s.Text()
+
+		if strings.HasPrefix(text, "\t") {
+			// The content of the line ends the information about it
+			line = 0
+		} else if line == 0 {
+			// Every line starts with the commit, its line in the commit and its line in the file
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected blame header %q", text)
+			}
+
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected blame header %q", text)
+			}
+			line = n
+		} else if strings.HasPrefix(text, "committer-time ") {
+			t, err := strconv.ParseInt(strings.TrimPrefix(text, "committer-time "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected committer time %q", text)
+			}
+			times[line] = t
+		}
+	}
+
+	return times, s.Err()
+}
+
+// prioritizeMutants orders the given mutants by the order in which they should be executed if not all of them can be executed.
+// Mutations of recently changed lines come first, then mutations without a result of a previous run and then the mutations are grouped by their mutator.
+func prioritizeMutants(opts *options, mutants []*mutant) {
+	files := map[string]map[int]int64{}
+	changed := make(map[*mutant]int64, len(mutants))
+
+	for _, m := range mutants {
+		times, ok := files[m.file]
+		if !ok {
+			var err error
+			times, err = gitLineTimes(m.file)
+			if err != nil {
+				debug(opts, "Cannot prioritize the mutations of %q by their changes: %v", m.file, err)
+			}
+			files[m.file] = times
+		}
+
+		for line := m.start.Line; line <= m.end.Line; line++ {
+			if times[line] > changed[m] {
+				changed[m] = times[line]
+			}
+		}
+	}
+
+	sort.SliceStable(mutants, func(i, j int) bool {
+		a, b := mutants[i], mutants[j]
+
+		if changed[a] != changed[b] {
+			return changed[a] > changed[b]
+		} else if (a.cached == nil) != (b.cached == nil) {
+			return a.cached == nil
+		}
+
+		return a.mutator < b.mutator
+	})
+}
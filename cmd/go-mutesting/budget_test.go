@@ -0,0 +1,81 @@
+package main
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBlame(t *testing.T) {
+	blame := `89fbac722346f75aa4e47ff415265f5b29f16bf3 1 1 2
+author go-mutesting
+committer-time 1500000000
+filename a.go
+	package a
+89fbac722346f75aa4e47ff415265f5b29f16bf3 2 2
+author go-mutesting
+committer-time 1500000000
+filename a.go
+	
+0000000000000000000000000000000000000000 3 3 1
+author Not Committed Yet
+committer-time 1600000000
+filename a.go
+	committer-time 1
+`
+
+	times, err := parseBlame(strings.NewReader(blame))
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]int64{
+		1: 1500000000,
+		2: 1500000000,
+		3: 1600000000,
+	}, times)
+}
+
+func TestPrioritizeMutants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mutesting-budget-")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}()
+
+	file := filepath.Join(dir, "a.go")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("package a\n\nvar a = 1\nvar b = 2\n"), 0644))
+
+	git(t, dir, "init")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-m", "Initial commit")
+
+	// The not yet committed line has been changed most recently
+	assert.Nil(t, ioutil.WriteFile(file, []byte("package a\n\nvar a = 1\nvar b = 3\n"), 0644))
+
+	mutantAt := func(line int, mutator string, cached bool) *mutant {
+		m := &mutant{
+			file:    file,
+			mutator: mutator,
+			start:   token.Position{Line: line},
+			end:     token.Position{Line: line},
+		}
+		if cached {
+			m.cached = &cachedResult{}
+		}
+
+		return m
+	}
+
+	committedCached := mutantAt(3, "a", true)
+	committedB := mutantAt(3, "b", false)
+	committedA := mutantAt(3, "a", false)
+	changed := mutantAt(4, "c", true)
+
+	mutants := []*mutant{committedCached, committedB, committedA, changed}
+	prioritizeMutants(&options{}, mutants)
+
+	assert.Equal(t, []*mutant{changed, committedA, committedB, committedCached}, mutants)
+}
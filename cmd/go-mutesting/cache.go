@@ -0,0 +1,175 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// cachedResult holds the outcome of an executed mutation in the result cache.
+type cachedResult struct {
+	ExitCode int
+	Results  []testResult
+}
+
+// resultCache stores the outcome of executed mutations, so that they are not executed again as long as neither the mutation, nor its package, its tests and their dependencies change.
+type resultCache struct {
+	dir string
+	// packages maps the path of every package to the checksum of everything which influences the outcome of its mutations.
+	packages map[string]string
+}
+
+// openResultCache opens the result cache for the packages of all given mutants.
+func openResultCache(opts *options, mutants []*mutant) (*resultCache, error) {
+	dir, err := cacheDirectory("results")
+	if err != nil {
+		return nil, err
+	}
+
+	// The standard library is identified by the version of Go instead of its files
+	version, err := exec.Command("go", "version").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the version of Go: %v", err)
+	}
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not determine the GOROOT: %v", err)
+	}
+
+	// The exec command is identified by its content, so that a changed script invalidates its results
+	var execChecksum string
+	if opts.Exec.Exec != "" {
+		execChecksum, err = commandChecksum(strings.Split(opts.Exec.Exec, " ")[0])
+		if err != nil {
+			return nil, fmt.Errorf("could not read the exec command %q: %v", opts.Exec.Exec, err)
+		}
+	}
+
+	c := &resultCache{
+		dir:      dir,
+		packages: map[string]string{},
+	}
+
+	for _, m := range mutants {
+		if _, ok := c.packages[m.pkgPath]; ok {
+			continue
+		}
+
+		pkgName := m.pkgPath
+		if opts.Test.Recursive {
+			pkgName += "/..."
+		}
+
+		checksum, err := dependencyChecksum(pkgName, strings.TrimSpace(string(goroot)))
+		if err != nil {
+			return nil, fmt.Errorf("could not load the dependencies of package %q: %v", pkgName, err)
+		}
+
+		h := md5.New()
+		for _, s := range []string{string(version), opts.Exec.Exec, execChecksum, fmt.Sprintf("%t", opts.Test.Recursive), checksum} {
+			_, _ = io.WriteString(h, s)
+			_, _ = io.WriteString(h, "\x00")
+		}
+
+		c.packages[m.pkgPath] = fmt.Sprintf("%x", h.Sum(nil))
+	}
+
+	return c, nil
+}
+
+// commandChecksum returns the checksum of the executable of the given command which is looked up like the command would be executed.
+func commandChecksum(command string) (string, error) {
+	file, err := exec.LookPath(command)
+	if err != nil {
+		return "", err
+	}
+
+	return fileChecksum(file)
+}
+
+// dependencyChecksum returns a checksum of all files of the given packages, their tests and all packages they depend on except the standard library.
+func dependencyChecksum(pkgName string, goroot string) (string, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
+		Tests: true,
+	}, pkgName)
+	if err != nil {
+		return "", err
+	}
+
+	var files []string
+	seen := map[string]struct{}{}
+
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		// The generated main package of the tests is derived from the test files
+		if strings.HasSuffix(p.ID, ".test") {
+			return
+		}
+
+		for _, file := range append(p.GoFiles, p.OtherFiles...) {
+			if strings.HasPrefix(file, goroot+string(filepath.Separator)) {
+				continue
+			}
+
+			if _, ok := seen[file]; !ok {
+				seen[file] = struct{}{}
+				files = append(files, file)
+			}
+		}
+	})
+
+	return filesChecksum(files)
+}
+
+// file returns the file of the cache which holds the result of the given mutant.
+func (c *resultCache) file(m *mutant) string {
+	h := md5.New()
+	for _, s := range []string{c.packages[m.pkgPath], filepath.Base(m.file), m.checksum, testsPattern(m.tests)} {
+		_, _ = io.WriteString(h, s)
+		_, _ = io.WriteString(h, "\x00")
+	}
+
+	return filepath.Join(c.dir, fmt.Sprintf("%x.json", h.Sum(nil)))
+}
+
+// Load returns the cached result of the given mutant, or nil if the mutant has not been executed yet.
+func (c *resultCache) Load(m *mutant) *cachedResult {
+	data, err := ioutil.ReadFile(c.file(m))
+	if err != nil {
+		return nil
+	}
+
+	var r cachedResult
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil
+	}
+
+	return &r
+}
+
+// Store caches the result of the given executed mutant.
+// Unknown exit codes are not cached since they do not state an outcome of the mutation. Timeouts are not cached either since they depend on the timeout of the mutation, which changes with the arguments and the measured duration of the tests.
+func (c *resultCache) Store(m *mutant, execExitCode int) error {
+	switch execExitCode {
+	case 0, 1, 2:
+	default:
+		return nil
+	}
+
+	data, err := json.Marshal(cachedResult{
+		ExitCode: execExitCode,
+		Results:  m.testResults,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.file(m), data, 0644)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultCache(t *testing.T) {
+	defer useTemporaryCache(t)()
+
+	dir, err := ioutil.TempDir("", "go-mutesting-exec-")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}()
+
+	script := filepath.Join(dir, "exec.sh")
+	assert.Nil(t, ioutil.WriteFile(script, []byte("#!/bin/sh\nexit 0\n"), 0755))
+
+	opts := &options{}
+	opts.Exec.Exec = script + " --verbose"
+
+	m := &mutant{
+		file:     "example.go",
+		pkgPath:  "github.com/zimmski/go-mutesting/example",
+		checksum: "0123",
+	}
+
+	c, err := openResultCache(opts, []*mutant{m})
+	assert.Nil(t, err)
+
+	assert.Nil(t, c.Store(m, 1))
+	if r := c.Load(m); assert.NotNil(t, r) {
+		assert.Equal(t, 1, r.ExitCode)
+	}
+
+	// Timeouts are not cached since they depend on the timeout of the mutation
+	assert.Nil(t, c.Store(m, execTimeout))
+	if r := c.Load(m); assert.NotNil(t, r) {
+		assert.Equal(t, 1, r.ExitCode)
+	}
+
+	// A changed exec command invalidates its results
+	assert.Nil(t, ioutil.WriteFile(script, []byte("#!/bin/sh\nexit 1\n"), 0755))
+	c, err = openResultCache(opts, []*mutant{m})
+	assert.Nil(t, err)
+	assert.Nil(t, c.Load(m))
+
+	// A missing exec command cannot be identified
+	opts.Exec.Exec = filepath.Join(dir, "missing.sh")
+	_, err = openResultCache(opts, []*mutant{m})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"go/token"
+	"path/filepath"
+
+	"golang.org/x/tools/cover"
+)
+
+// markNotCovered marks all given mutants of the given package whose changed node is not covered by the given coverage profile.
+func markNotCovered(profileFile string, pkgPath string, mutants []*mutant) error {
+	profiles, err := cover.ParseProfiles(profileFile)
+	if err != nil {
+		return err
+	}
+
+	blocks := map[string][]cover.ProfileBlock{}
+	for _, p := range profiles {
+		blocks[p.FileName] = p.Blocks
+	}
+
+	for _, m := range mutants {
+		if m.pkgPath != pkgPath {
+			continue
+		}
+
+		m.notCovered = !covered(blocks[coverageFile(m)], m.start, m.end)
+	}
+
+	return nil
+}
+
+// coverageFile returns the name of the file of the given mutant which is used by coverage profiles.
+func coverageFile(m *mutant) string {
+	// Coverage profiles identify files by the import path of their package
+	return m.pkgPath + "/" + filepath.Base(m.file)
+}
+
+// covered returns false if all blocks which overlap the given range have not been executed.
+// Ranges which do not overlap any block, e.g. declarations outside of functions, are always covered.
+func covered(blocks []cover.ProfileBlock, start token.Position, end token.Position) bool {
+	overlaps := false
+
+	for _, b := range blocks {
+		if !before(b.StartLine, b.StartCol, end.Line, end.Column) || !before(start.Line, start.Column, b.EndLine, b.EndCol) {
+			continue
+		}
+
+		if b.Count > 0 {
+			return true
+		}
+
+		overlaps = true
+	}
+
+	return !overlaps
+}
+
+// before returns true if the first position is before the second position.
+func before(line int, column int, otherLine int, otherColumn int) bool {
+	return line < otherLine || (line == otherLine && column < otherColumn)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/cover"
+)
+
+func TestCovered(t *testing.T) {
+	blocks := []cover.ProfileBlock{
+		{StartLine: 3, StartCol: 20, EndLine: 5, EndCol: 12, NumStmt: 2, Count: 1},
+		{StartLine: 5, StartCol: 12, EndLine: 7, EndCol: 3, NumStmt: 1, Count: 0},
+		{StartLine: 9, StartCol: 2, EndLine: 9, EndCol: 10, NumStmt: 1, Count: 1},
+	}
+
+	position := func(line int, column int) token.Position {
+		return token.Position{Line: line, Column: column}
+	}
+
+	// Executed block
+	assert.True(t, covered(blocks, position(4, 2), position(4, 10)))
+	// Block which has not been executed
+	assert.False(t, covered(blocks, position(6, 3), position(6, 20)))
+	// Overlaps an executed block and a block which has not been executed
+	assert.True(t, covered(blocks, position(5, 2), position(7, 3)))
+	// Outside of all blocks
+	assert.True(t, covered(blocks, position(1, 1), position(1, 20)))
+	// Next to a block which has not been executed
+	assert.True(t, covered(blocks, position(7, 3), position(8, 1)))
+}
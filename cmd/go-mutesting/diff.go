@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lineRange holds the lines from start to end including both.
+type lineRange struct {
+	start int
+	end   int
+}
+
+// changedLines maps the absolute paths of changed files to their changed lines.
+type changedLines map[string][]lineRange
+
+// File returns true if the given file has been changed.
+func (c changedLines) File(file string) bool {
+	_, ok := c[absFile(file)]
+
+	return ok
+}
+
+// Intersects returns true if at least one of the given lines of the given file has been changed.
+func (c changedLines) Intersects(file string, startLine int, endLine int) bool {
+	for _, r := range c[absFile(file)] {
+		if r.start <= endLine && startLine <= r.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// absFile returns the absolute path of the given file without any symbolic links, so that it can be compared to the files of a diff.
+func absFile(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+
+	return abs
+}
+
+// gitChangedLines returns the lines which have been changed since the given ref, or the lines of the staged changes.
+func gitChangedLines(ref string, staged bool) (changedLines, error) {
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("could not find the git repository: %v\n%s", err, root)
+	}
+
+	// The prefixes and paths of the diff must not depend on the configuration of the user
+	args := []string{"-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff", "--no-relative", "--src-prefix=a/", "--dst-prefix=b/", "-U0"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if ref != "" {
+		args = append(args, ref)
+	}
+	args = append(args, "--")
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("could not diff: %v\n%s", err, stderr.Bytes())
+	}
+
+	return parseDiff(bytes.NewReader(out), strings.TrimSpace(string(root)))
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// parseDiff parses the given unified diff with paths relative to the given root and returns the changed lines of the new files.
+// Since deleted lines do not exist anymore, the lines before and after them count as changed.
+func parseDiff(r io.Reader, root string) (changedLines, error) {
+	lines := changedLines{}
+
+	var file string
+	var oldLines, newLines int
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for s.Scan() {
+		line := s.Text()
+
+		// Lines of a hunk could look like headers
+		if oldLines > 0 || newLines > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLines--
+			case strings.HasPrefix(line, "+"):
+				newLines--
+			case strings.HasPrefix(line, " "):
+				oldLines--
+				newLines--
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(line, "diff ") {
+			file = ""
+		} else if strings.HasPrefix(line, "+++ ") {
+			name := strings.TrimPrefix(line, "+++ ")
+			if name == "/dev/null" {
+				continue
+			}
+
+			if strings.HasPrefix(name, `"`) {
+				unquoted, err := strconv.Unquote(name)
+				if err != nil {
+					return nil, fmt.Errorf("could not unquote file name %s: %v", name, err)
+				}
+				name = unquoted
+			}
+
+			file = filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/")))
+			lines[file] = nil
+		} else if m := hunkHeader.FindStringSubmatch(line); m != nil && file != "" {
+			oldLines = 1
+			if m[1] != "" {
+				oldLines, _ = strconv.Atoi(m[1])
+			}
+			start, _ := strconv.Atoi(m[2])
+			newLines = 1
+			if m[3] != "" {
+				newLines, _ = strconv.Atoi(m[3])
+			}
+
+			if newLines == 0 {
+				lines[file] = append(lines[file], lineRange{start: start, end: start + 1})
+			} else {
+				lines[file] = append(lines[file], lineRange{start: start, end: start + newLines - 1})
+			}
+		}
+	}
+
+	return lines, s.Err()
+}
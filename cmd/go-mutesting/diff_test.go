@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/a.go b/a.go
+index b16f1f2..9daecb6 100644
+--- a/a.go
++++ b/a.go
+@@ -3 +3,2 @@ package a
+-func a() {}
++func a() {
++}
+@@ -10,2 +10,0 @@ func b() {
+--- removed line which looks like a header
+-+++ b/removed
+diff --git a/b/c.go b/b/c.go
+new file mode 100644
+index 0000000..9daecb6
+--- /dev/null
++++ b/b/c.go
+@@ -0,0 +1,3 @@
++package c
++
++func c() {}
+diff --git a/d.go b/d.go
+deleted file mode 100644
+index 9daecb6..0000000
+--- a/d.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package d
+`
+
+	lines, err := parseDiff(strings.NewReader(diff), "/repository")
+	assert.Nil(t, err)
+	assert.Equal(t, changedLines{
+		"/repository/a.go":   []lineRange{{start: 3, end: 4}, {start: 10, end: 11}},
+		"/repository/b/c.go": []lineRange{{start: 1, end: 3}},
+	}, lines)
+
+	assert.True(t, lines.Intersects("/repository/a.go", 4, 6))
+	assert.True(t, lines.Intersects("/repository/a.go", 11, 11))
+	assert.False(t, lines.Intersects("/repository/a.go", 5, 9))
+	assert.False(t, lines.Intersects("/repository/d.go", 1, 1))
+}
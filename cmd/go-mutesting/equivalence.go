@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+)
+
+// compiledCode holds the compiled code of a package.
+type compiledCode struct {
+	// checksum is the checksum of the assembly of the package without any source positions.
+	checksum string
+	// lines holds every "file:line" of the package which produced code.
+	lines map[string]struct{}
+}
+
+// sourcePosition matches the source positions of the assembly output of the compiler.
+var sourcePosition = regexp.MustCompile(`\((\S+):(\d+)\)`)
+
+// newCompiledCode normalizes the given assembly output of the compiler.
+// Source positions are removed since mutations can move code to other lines without changing it.
+func newCompiledCode(assembly []byte) *compiledCode {
+	c := &compiledCode{
+		lines: map[string]struct{}{},
+	}
+
+	for _, m := range sourcePosition.FindAllSubmatch(assembly, -1) {
+		c.lines[string(m[1])+":"+string(m[2])] = struct{}{}
+	}
+
+	c.checksum = fmt.Sprintf("%x", md5.Sum(sourcePosition.ReplaceAll(assembly, nil)))
+
+	return c
+}
+
+// compilePackage compiles the given package, using the given overlay file if it is not empty, and returns its compiled code.
+func compilePackage(pkgPath string, overlayFile string) (*compiledCode, error) {
+	// Trimmed paths keep the temporary directories of mutations out of the assembly
+	args := []string{"build", "-o", os.DevNull, "-trimpath", "-gcflags=" + pkgPath + "=-S -dwarf=false"}
+	if overlayFile != "" {
+		args = append(args, "-overlay", overlayFile)
+	}
+
+	out, err := exec.Command("go", append(args, pkgPath)...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, out)
+	}
+
+	return newCompiledCode(out), nil
+}
+
+// producesCode returns true if at least one of the lines which are changed by the given mutant produced code.
+// Mutations of lines without code, e.g. constants or generic functions which are never instantiated, cannot be compared.
+func (c *compiledCode) producesCode(m *mutant) bool {
+	file := coverageFile(m)
+
+	for line := m.start.Line; line <= m.end.Line; line++ {
+		if _, ok := c.lines[fmt.Sprintf("%s:%d", file, line)]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detectEquivalents compiles every package of the given mutants with and without each of its mutations.
+// Mutants which compile to the same code as their original package are marked as equivalent, mutants which compile to the same code as a previous mutant are marked as duplicate of that mutant.
+func detectEquivalents(opts *options, mutants []*mutant) error {
+	var pkgPaths []string
+	pkgMutants := map[string][]*mutant{}
+
+	for _, m := range mutants {
+		if m.compileError != nil {
+			continue
+		}
+
+		if _, ok := pkgMutants[m.pkgPath]; !ok {
+			pkgPaths = append(pkgPaths, m.pkgPath)
+		}
+		pkgMutants[m.pkgPath] = append(pkgMutants[m.pkgPath], m)
+	}
+
+	for _, pkgPath := range pkgPaths {
+		original, err := compilePackage(pkgPath, "")
+		if err != nil {
+			return fmt.Errorf("could not compile package %q: %v", pkgPath, err)
+		}
+
+		ms := pkgMutants[pkgPath]
+		compiled := make([]*compiledCode, len(ms))
+
+		queue := make(chan int)
+		var wg sync.WaitGroup
+
+		for i := uint(0); i < opts.Exec.Jobs; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for k := range queue {
+					overlayFile, err := writeOverlay(ms[k])
+					if err == nil {
+						compiled[k], err = compilePackage(pkgPath, overlayFile)
+					}
+					if err != nil {
+						debug(opts, "Could not compile %q, we do not compare it: %v", ms[k].mutationFile, err)
+					}
+				}
+			}()
+		}
+
+		for k := range ms {
+			queue <- k
+		}
+		close(queue)
+
+		wg.Wait()
+
+		seen := map[string]*mutant{}
+
+		for k, m := range ms {
+			c := compiled[k]
+			if c == nil || !original.producesCode(m) {
+				continue
+			}
+
+			if c.checksum == original.checksum {
+				m.equivalent = true
+			} else if other, ok := seen[c.checksum]; ok {
+				m.duplicateOf = other
+			} else {
+				seen[c.checksum] = m
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCompiledCode(t *testing.T) {
+	original := newCompiledCode([]byte(`a.F STEXT nosplit size=4 args=0x8 locals=0x0 funcid=0x0 align=0x0
+	0x0000 00000 (a/a.go:3)	TEXT	a.F(SB), NOSPLIT|NOFRAME|ABIInternal, $0-8
+	0x0000 00000 (a/a.go:4)	INCQ	AX
+	0x0003 00003 (a/a.go:4)	RET
+`))
+	// The same code on other lines
+	moved := newCompiledCode([]byte(`a.F STEXT nosplit size=4 args=0x8 locals=0x0 funcid=0x0 align=0x0
+	0x0000 00000 (a/a.go:3)	TEXT	a.F(SB), NOSPLIT|NOFRAME|ABIInternal, $0-8
+	0x0000 00000 (a/a.go:6)	INCQ	AX
+	0x0003 00003 (a/a.go:6)	RET
+`))
+	changed := newCompiledCode([]byte(`a.F STEXT nosplit size=4 args=0x8 locals=0x0 funcid=0x0 align=0x0
+	0x0000 00000 (a/a.go:3)	TEXT	a.F(SB), NOSPLIT|NOFRAME|ABIInternal, $0-8
+	0x0000 00000 (a/a.go:4)	DECQ	AX
+	0x0003 00003 (a/a.go:4)	RET
+`))
+
+	assert.Equal(t, original.checksum, moved.checksum)
+	assert.NotEqual(t, original.checksum, changed.checksum)
+
+	m := &mutant{
+		file:    "/src/a/a.go",
+		pkgPath: "a",
+		start:   token.Position{Line: 4},
+		end:     token.Position{Line: 5},
+	}
+	assert.True(t, original.producesCode(m))
+
+	// Lines without code, e.g. constants, cannot be compared
+	m.start.Line, m.end.Line = 1, 2
+	assert.False(t, original.producesCode(m))
+}
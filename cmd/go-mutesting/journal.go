@@ -0,0 +1,218 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/zimmski/osutil"
+)
+
+const journalDirectory = ".go-mutesting"
+
+// journalEntry records an original file which might be replaced in place by a mutation.
+type journalEntry struct {
+	File     string
+	Backup   string
+	Checksum string
+}
+
+// journal records all original files which might be currently replaced in place by mutations, so they can be restored after an interrupted run.
+type journal struct {
+	sync.Mutex
+
+	directory string
+	entries   map[string]journalEntry
+}
+
+// openJournal opens the journal of the given directory, including all entries of an interrupted run.
+func openJournal(directory string) (*journal, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &journal{
+		directory: directory,
+		entries:   map[string]journalEntry{},
+	}
+
+	data, err := ioutil.ReadFile(j.file())
+	if os.IsNotExist(err) {
+		return j, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &j.entries); err != nil {
+		return nil, fmt.Errorf("could not parse journal %q: %v", j.file(), err)
+	}
+
+	return j, nil
+}
+
+func (j *journal) file() string {
+	return filepath.Join(j.directory, "journal.json")
+}
+
+// Stale returns true if the journal holds entries of an interrupted run.
+func (j *journal) Stale() bool {
+	j.Lock()
+	defer j.Unlock()
+
+	return len(j.entries) > 0
+}
+
+// Add backups the given pristine copy of the given original file and records it for the given mutation file before the original file gets replaced.
+// The backup is not taken from the original file itself since it could be currently replaced by another mutation.
+func (j *journal) Add(file string, originalFile string, mutationFile string) error {
+	j.Lock()
+	defer j.Unlock()
+
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	checksum, err := fileChecksum(originalFile)
+	if err != nil {
+		return err
+	}
+
+	backup := filepath.Join(j.directory, "backup", checksum)
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return err
+	}
+	if err := osutil.CopyFile(originalFile, backup); err != nil {
+		return err
+	}
+
+	j.entries[mutationFile] = journalEntry{
+		File:     file,
+		Backup:   backup,
+		Checksum: checksum,
+	}
+
+	return j.save()
+}
+
+// Restore restores the original file which has been recorded for the given mutation file.
+func (j *journal) Restore(mutationFile string) error {
+	j.Lock()
+	defer j.Unlock()
+
+	return j.restore(mutationFile)
+}
+
+// RestoreAll restores all recorded original files and returns the restored files.
+func (j *journal) RestoreAll() ([]string, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	var mutationFiles []string
+	for mutationFile := range j.entries {
+		mutationFiles = append(mutationFiles, mutationFile)
+	}
+	sort.Strings(mutationFiles)
+
+	var restored []string
+	for _, mutationFile := range mutationFiles {
+		file := j.entries[mutationFile].File
+
+		if err := j.restore(mutationFile); err != nil {
+			return restored, err
+		}
+
+		restored = append(restored, file)
+	}
+
+	return restored, nil
+}
+
+func (j *journal) restore(mutationFile string) error {
+	e, ok := j.entries[mutationFile]
+	if !ok {
+		return nil
+	}
+
+	if checksum, err := fileChecksum(e.File); err != nil || checksum != e.Checksum {
+		if err := osutil.CopyFile(e.Backup, e.File); err != nil {
+			return fmt.Errorf("could not restore %q: %v", e.File, err)
+		}
+	}
+
+	// The in place replacement moves the original file aside which is not needed anymore
+	if checksum, err := fileChecksum(e.File + ".tmp"); err == nil && checksum == e.Checksum {
+		_ = os.Remove(e.File + ".tmp")
+	}
+
+	return j.remove(mutationFile)
+}
+
+func (j *journal) remove(mutationFile string) error {
+	e, ok := j.entries[mutationFile]
+	if !ok {
+		return nil
+	}
+
+	delete(j.entries, mutationFile)
+
+	used := false
+	for _, o := range j.entries {
+		if o.Backup == e.Backup {
+			used = true
+
+			break
+		}
+	}
+	if !used {
+		_ = os.Remove(e.Backup)
+	}
+
+	return j.save()
+}
+
+func (j *journal) save() error {
+	if len(j.entries) == 0 {
+		if err := os.Remove(j.file()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		// Only remove the directories if nothing else is left in them
+		_ = os.Remove(filepath.Join(j.directory, "backup"))
+		_ = os.Remove(j.directory)
+
+		return nil
+	}
+
+	if err := os.MkdirAll(j.directory, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(j.entries, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// Write the journal atomically so an interruption cannot leave a broken journal behind
+	tmpFile := j.file() + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, j.file())
+}
+
+func fileChecksum(file string) (string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", md5.Sum(data)), nil
+}
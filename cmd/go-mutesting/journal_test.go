@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mutesting-journal-")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}()
+
+	file := filepath.Join(dir, "file.go")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("original"), 0644))
+	originalFile := filepath.Join(dir, "file.go.original")
+	assert.Nil(t, ioutil.WriteFile(originalFile, []byte("original"), 0644))
+
+	journalDir := filepath.Join(dir, journalDirectory)
+
+	j, err := openJournal(journalDir)
+	assert.Nil(t, err)
+	assert.False(t, j.Stale())
+
+	// Replace the original file in place like an interrupted run would
+	assert.Nil(t, j.Add(file, originalFile, "file.go.0"))
+	assert.Nil(t, os.Rename(file, file+".tmp"))
+	assert.Nil(t, ioutil.WriteFile(file, []byte("mutation"), 0644))
+
+	// The backup is taken from the pristine copy even if another mutation currently replaces the original file
+	assert.Nil(t, j.Add(file, originalFile, "file.go.1"))
+
+	// A new run finds the journal of the interrupted run
+	j, err = openJournal(journalDir)
+	assert.Nil(t, err)
+	assert.True(t, j.Stale())
+
+	restored, err := j.RestoreAll()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{file, file}, restored)
+	assert.False(t, j.Stale())
+
+	data, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, "original", string(data))
+
+	_, err = os.Stat(file + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(journalDir)
+	assert.True(t, os.IsNotExist(err))
+}
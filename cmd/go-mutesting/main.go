@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -11,12 +12,16 @@ import (
 	"go/types"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 	"github.com/zimmski/go-tool/importing"
@@ -26,10 +31,14 @@ import (
 	"github.com/zimmski/go-mutesting/astutil"
 	"github.com/zimmski/go-mutesting/mutator"
 	_ "github.com/zimmski/go-mutesting/mutator/branch"
+	_ "github.com/zimmski/go-mutesting/mutator/error"
 	_ "github.com/zimmski/go-mutesting/mutator/expression"
 	_ "github.com/zimmski/go-mutesting/mutator/statement"
 )
 
+// execTimeout is used as exit code of an exec command which has been killed because it exceeded the timeout.
+const execTimeout = -1
+
 const (
 	returnOk = iota
 	returnHelp
@@ -57,16 +66,39 @@ type options struct {
 	} `group:"Mutator options"`
 
 	Filter struct {
-		Match string `long:"match" description:"Only functions are mutated that confirm to the arguments regex"`
+		Coverage    bool     `long:"coverage" description:"Do not execute mutations which are not covered by the tests of their package and report them as not covered"`
+		Diff        string   `long:"diff" description:"Only mutate lines which have been changed since the given git ref"`
+		Equivalence bool     `long:"equivalence" description:"Compile every mutation and do not execute mutations which compile to the same code as the original (reported as equivalent) or as another mutation (reported as duplicate)"`
+		Match       string   `long:"match" description:"Only functions are mutated that confirm to the arguments regex"`
+		Sample      string   `long:"sample" description:"Only execute a random sample of the mutations given as number (e.g. 500) or percentage (e.g. 20%)"`
+		Seed        int64    `long:"seed" description:"Seed which makes the random sample reproducible (by default a random seed is used and printed)"`
+		Staged      bool     `long:"staged" description:"Only mutate lines which are changed by the staged changes of git (or since the git ref of --diff)"`
+		Static      bool     `long:"static-analysis" description:"Do not execute mutations which the static analysis of their packages finds to be probably equivalent (e.g. dead stores and removed calls of side-effect free functions) or unreachable from any exported function, method, init function or test"`
+		Stratify    []string `long:"sample-stratify" choice:"mutator" choice:"package" description:"Sample the mutations of every mutator or package on their own, so that each of them is part of the sample"`
 	} `group:"Filter options"`
 
 	Exec struct {
-		Exec    string `long:"exec" description:"Execute this command for every mutation (by default the built-in exec command is used)"`
-		NoExec  bool   `long:"no-exec" description:"Skip the built-in exec command and just generate the mutations"`
-		Timeout uint   `long:"exec-timeout" description:"Sets a timeout for the command execution (in seconds)" default:"10"`
+		Budget          time.Duration `long:"budget" description:"Time budget of the whole run (e.g. 30m) after which no further mutations are executed, the mutations are then executed by priority (recently changed lines, mutations without cached result, mutator)"`
+		Exec            string        `long:"exec" description:"Execute this command for every mutation (by default the built-in exec command is used)"`
+		InPlace         bool          `long:"exec-in-place" description:"Let the built-in exec command replace the original file with the mutation instead of using an overlay"`
+		Jobs            uint          `long:"jobs" description:"Number of mutations which are executed in parallel" default:"1"`
+		NoCache         bool          `long:"no-cache" description:"Execute all mutations even if their results are cached and do not cache any results"`
+		NoExec          bool          `long:"no-exec" description:"Skip the built-in exec command and just generate the mutations"`
+		Parallel        bool          `long:"exec-parallel" description:"Allow parallel jobs for the exec command of --exec which must then only use MUTATE_OVERLAY and never replace the original file in place"`
+		Schemata        bool          `long:"exec-schemata" description:"Let the built-in exec command build one test binary per package which contains all mutations as runtime switches instead of compiling every mutation on its own"`
+		Timeout         uint          `long:"exec-timeout" description:"Sets a fixed timeout for the command execution (in seconds) after which the command and all its child processes are killed (by default the timeout is derived from the test duration of the package)"`
+		TimeoutConstant uint          `long:"exec-timeout-constant" description:"Constant which is added to the derived timeout (in seconds)" default:"5"`
+		TimeoutFactor   float64       `long:"exec-timeout-factor" description:"Factor which is applied to the test duration of the package for the derived timeout" default:"3"`
 	} `group:"Exec options"`
 
+	Report struct {
+		Dominators bool   `long:"report-dominators" description:"Print the minimal set of mutations which subsumes all other mutations and its mutation score"`
+		JSON       string `long:"report-json" description:"Write the results of all mutations including the outcome of every executed test as JSON to the given file"`
+		KillMatrix bool   `long:"report-kill-matrix" description:"Print the effectiveness of every test, the tests which never killed a mutation and a minimal set of tests which kills the same mutations"`
+	} `group:"Report options"`
+
 	Test struct {
+		Covering  bool `long:"test-covering" description:"Only execute the tests which execute the mutated lines (determined by executing every test on its own with coverage)"`
 		Recursive bool `long:"test-recursive" description:"Defines if the executer should test recursively"`
 	} `group:"Test options"`
 
@@ -111,6 +143,30 @@ func checkArguments(args []string, opts *options) (bool, int) {
 		opts.General.Verbose = true
 	}
 
+	if opts.Exec.Jobs == 0 {
+		return true, exitError("The number of jobs must be at least 1")
+	} else if opts.Exec.Jobs > 1 && opts.Exec.InPlace {
+		return true, exitError("Parallel jobs cannot replace the original file in place")
+	} else if opts.Exec.Jobs > 1 && opts.Exec.Exec != "" && !opts.Exec.Parallel {
+		return true, exitError("Parallel jobs are only supported by the built-in exec command, unless --exec-parallel states that the exec command does not replace the original file in place")
+	} else if opts.Exec.Budget < 0 {
+		return true, exitError("The time budget must not be negative")
+	} else if opts.Exec.TimeoutFactor < 0 {
+		return true, exitError("The timeout factor must not be negative")
+	} else if opts.Exec.Schemata && (opts.Exec.Exec != "" || opts.Exec.InPlace || opts.Test.Recursive) {
+		return true, exitError("Schemata can only be used by the built-in exec command with an overlay and without recursive tests")
+	} else if opts.Filter.Equivalence && opts.Exec.InPlace {
+		return true, exitError("Mutations can only be compared with an overlay and not in place")
+	} else if opts.Filter.Sample == "" && (opts.Filter.Seed != 0 || len(opts.Filter.Stratify) > 0) {
+		return true, exitError("A seed and strata can only be used together with a sample")
+	}
+
+	if opts.Filter.Sample != "" {
+		if _, _, err := parseSampleSize(opts.Filter.Sample); err != nil {
+			return true, exitError("The sample size is not valid: %v", err)
+		}
+	}
+
 	return false, 0
 }
 
@@ -137,11 +193,75 @@ type mutatorItem struct {
 	Mutator mutator.Mutator
 }
 
+// mutant holds everything which is needed to execute one saved mutation.
+type mutant struct {
+	checksum     string
+	file         string
+	mutationFile string
+	// originalFile is the pristine copy of the original file.
+	originalFile string
+	mutator      string
+	pkgPath      string
+	timeout      time.Duration
+	// tests holds the tests which should be executed for the mutation, or nil if all tests should be executed.
+	tests []string
+
+	// start and end define the source range of the changed node of the mutation.
+	start token.Position
+	end   token.Position
+
+	notCovered bool
+	// fixed is true if unused imports or variables of the mutation have been fixed.
+	fixed bool
+	// compileError holds the type error of the mutation if it does not compile.
+	compileError error
+	// equivalent is true if the mutation compiles to the same code as the original.
+	equivalent bool
+	// duplicateOf holds the mutant which compiles to the same code as the mutation.
+	duplicateOf *mutant
+	// finding holds why the static analysis found that the mutation cannot be killed.
+	finding *finding
+
+	// schema holds the mutated statement if the mutation can be switched at runtime, or nil if it must be compiled on its own.
+	schema *schemaSite
+	// cached holds the cached result of the mutation, or nil if the mutation has to be executed.
+	cached *cachedResult
+
+	// schemaBinary is the test binary which contains the mutation as runtime switch with the identifier schemaID.
+	schemaBinary string
+	schemaID     int
+
+	// status holds the reported status of the mutation after its execution.
+	status string
+	// testResults holds the outcome of every test which was executed by the built-in exec command for the mutation.
+	testResults []testResult
+}
+
+// executable returns true if the mutation has to be executed to know whether it is killed.
+func (m *mutant) executable() bool {
+	return !m.notCovered && m.compileError == nil && !m.equivalent && m.duplicateOf == nil && m.finding == nil
+}
+
 type mutationStats struct {
-	passed     int
-	failed     int
-	duplicated int
+	passed        int
+	failed        int
+	duplicated    int
+	compileErrors int
+	// equivalent counts the mutations which compile to the same code as the original and are therefore not part of the total.
+	equivalent int
+	// probablyEquivalent and unreachable count the findings of the static analysis which are not part of the total.
+	probablyEquivalent int
+	unreachable        int
+	// rescued counts the mutations which only compile because unused imports or variables have been fixed.
+	rescued    int
 	skipped    int
+	timedOut   int
+	notCovered int
+	// unexecuted counts the mutations which have not been executed since the time budget has been used up.
+	unexecuted int
+
+	// sampled is true if only a sample of the mutations has been executed.
+	sampled bool
 }
 
 func (ms *mutationStats) Score() float64 {
@@ -151,17 +271,24 @@ func (ms *mutationStats) Score() float64 {
 		return 0.0
 	}
 
-	return float64(ms.passed) / float64(total)
+	// Mutations which lead to a timeout are killed by the tests
+	return float64(ms.passed+ms.timedOut) / float64(total)
 }
 
 func (ms *mutationStats) Total() int {
-	return ms.passed + ms.failed + ms.skipped
+	return ms.passed + ms.failed + ms.skipped + ms.timedOut + ms.notCovered
 }
 
 func mainCmd(args []string) int {
+	start := time.Now()
+
 	var opts = &options{}
 	var mutationBlackList = map[string]struct{}{}
 
+	if len(args) > 0 && args[0] == "restore" {
+		return restoreCmd()
+	}
+
 	if exit, exitCode := checkArguments(args, opts); exit {
 		return exitCode
 	}
@@ -171,6 +298,26 @@ func mainCmd(args []string) int {
 		return exitError("Could not find any suitable Go source files")
 	}
 
+	var diff changedLines
+	if opts.Filter.Diff != "" || opts.Filter.Staged {
+		var err error
+		diff, err = gitChangedLines(opts.Filter.Diff, opts.Filter.Staged)
+		if err != nil {
+			return exitError("Could not determine the changed lines: %v", err)
+		}
+
+		// Files without any changes are not even parsed
+		var changedFiles []string
+		for _, file := range files {
+			if diff.File(file) {
+				changedFiles = append(changedFiles, file)
+			} else {
+				debug(opts, "Ignore %q since it has not been changed", file)
+			}
+		}
+		files = changedFiles
+	}
+
 	if opts.Files.ListFiles {
 		for _, file := range files {
 			fmt.Println(file)
@@ -194,6 +341,14 @@ func mainCmd(args []string) int {
 		return returnOk
 	}
 
+	j, err := openJournal(journalDirectory)
+	if err != nil {
+		return exitError("Could not open journal: %v", err)
+	}
+	if j.Stale() {
+		return exitError("Found the journal %q of an interrupted run. Run \"go-mutesting restore\" to restore the original files first.", j.file())
+	}
+
 	if len(opts.Files.Blacklist) > 0 {
 		for _, f := range opts.Files.Blacklist {
 			c, err := ioutil.ReadFile(f)
@@ -250,14 +405,18 @@ MUTATOR:
 	}
 
 	stats := &mutationStats{}
+	var mutants []*mutant
 
-	for _, file := range files {
-		verbose(opts, "Mutate %q", file)
+	// All packages are loaded and type-checked at once instead of once for every file
+	typeCheckedFiles, err := mutesting.ParseAndTypeCheckFiles(files)
+	if err != nil {
+		return exitError(err.Error())
+	}
 
-		src, fset, pkg, info, err := mutesting.ParseAndTypeCheckFile(file)
-		if err != nil {
-			return exitError(err.Error())
-		}
+	for _, f := range typeCheckedFiles {
+		file, src, fset, pkg, info := f.File, f.Src, f.Fset, f.Pkg, f.Info
+
+		verbose(opts, "Mutate %q", file)
 
 		err = os.MkdirAll(tmpDir+"/"+filepath.Dir(file), 0755)
 		if err != nil {
@@ -274,6 +433,7 @@ MUTATOR:
 		debug(opts, "Save original into %q", originalFile)
 
 		mutationID := 0
+		fileMutants := len(mutants)
 
 		if opts.Filter.Match != "" {
 			m, err := regexp.Compile(opts.Filter.Match)
@@ -283,12 +443,123 @@ MUTATOR:
 
 			for _, f := range astutil.Functions(src) {
 				if m.MatchString(f.Name.Name) {
-					mutationID = mutate(opts, mutators, mutationBlackList, mutationID, pkg, info, file, fset, src, f, tmpFile, execs, stats)
+					mutationID, mutants = mutate(opts, mutators, mutationBlackList, diff, mutationID, pkg, info, file, fset, src, f, tmpFile, mutants, stats)
 				}
 			}
 		} else {
-			_ = mutate(opts, mutators, mutationBlackList, mutationID, pkg, info, file, fset, src, src, tmpFile, execs, stats)
+			_, mutants = mutate(opts, mutators, mutationBlackList, diff, mutationID, pkg, info, file, fset, src, src, tmpFile, mutants, stats)
+		}
+
+		// Mutations which do not compile are detected without invoking the go command
+		for _, m := range mutants[fileMutants:] {
+			mutation, err := ioutil.ReadFile(m.mutationFile)
+			if err != nil {
+				return exitError("Could not read mutation %q: %v", m.mutationFile, err)
+			}
+
+			m.compileError = mutesting.TypeCheckMutation(f, mutation)
+		}
+	}
+
+	if opts.Filter.Sample != "" {
+		seed := opts.Filter.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+
+		sample := sampleMutants(opts, mutants, seed)
+		fmt.Printf("Sampled %d of %d mutations with the seed %d\n", len(sample), len(mutants), seed)
+
+		mutants = sample
+		stats.sampled = true
+	}
+
+	for _, m := range mutants {
+		if m.fixed && m.compileError == nil {
+			stats.rescued++
+		}
+	}
+
+	if !opts.Exec.NoExec {
+		// Restore all original files which are replaced in place if we get interrupted
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGHUP, syscall.SIGTERM)
+		go func() {
+			if _, ok := <-signals; !ok {
+				return
+			}
+
+			killRunningCommands()
+
+			restored, err := j.RestoreAll()
+			for _, file := range restored {
+				fmt.Printf("Restored %q\n", file)
+			}
+			if err != nil {
+				os.Exit(exitError("Could not restore all original files: %v", err))
+			}
+
+			os.Exit(exitError("Interrupted"))
+		}()
+
+		// The tests must pass without any mutation, otherwise every mutation would be reported as killed
+		if err := runBaselines(opts, j, tmpDir, mutants); err != nil {
+			signal.Stop(signals)
+			close(signals)
+
+			return exitError("Could not run the tests without any mutation: %v", err)
+		}
+
+		if opts.Filter.Static {
+			if err := analyzeMutants(opts, mutants); err != nil {
+				signal.Stop(signals)
+				close(signals)
+
+				return exitError("Could not analyze the mutations statically: %v", err)
+			}
+		}
+
+		if opts.Filter.Equivalence {
+			if err := detectEquivalents(opts, mutants); err != nil {
+				signal.Stop(signals)
+				close(signals)
+
+				return exitError("Could not compare the compiled mutations: %v", err)
+			}
+		}
+
+		var c *resultCache
+		if !opts.Exec.NoCache {
+			c, err = openResultCache(opts, mutants)
+			if err != nil {
+				signal.Stop(signals)
+				close(signals)
+
+				return exitError("Could not open the result cache: %v", err)
+			}
+
+			for _, m := range mutants {
+				if m.executable() {
+					m.cached = c.Load(m)
+				}
+			}
 		}
+
+		var deadline time.Time
+		if opts.Exec.Budget > 0 {
+			deadline = start.Add(opts.Exec.Budget)
+
+			prioritizeMutants(opts, mutants)
+		}
+
+		if opts.Exec.Schemata {
+			buildSchemata(opts, tmpDir, mutants)
+		}
+
+		executeMutants(opts, j, c, mutants, execs, deadline, stats)
+
+		signal.Stop(signals)
+		close(signals)
 	}
 
 	if !opts.General.DoNotRemoveTmpFolder {
@@ -300,7 +571,40 @@ MUTATOR:
 	}
 
 	if !opts.Exec.NoExec {
-		fmt.Printf("The mutation score is %f (%d passed, %d failed, %d duplicated, %d skipped, total is %d)\n", stats.Score(), stats.passed, stats.failed, stats.duplicated, stats.skipped, stats.Total())
+		r := newReport(mutants, stats)
+
+		if opts.Report.JSON != "" {
+			if err := writeJSONReport(opts.Report.JSON, r); err != nil {
+				return exitError("Could not write report %q: %v", opts.Report.JSON, err)
+			}
+		}
+
+		if opts.Report.KillMatrix {
+			printKillMatrix(os.Stdout, r)
+		}
+
+		fmt.Printf("The mutation score is %f (%d passed, %d failed, %d duplicated, %d compile errors, %d skipped, %d timed out, %d not covered, total is %d)\n", stats.Score(), stats.passed, stats.failed, stats.duplicated, stats.compileErrors, stats.skipped, stats.timedOut, stats.notCovered, stats.Total())
+
+		if stats.unexecuted > 0 {
+			fmt.Printf("%d mutations have not been executed since the time budget of %s has been used up\n", stats.unexecuted, opts.Exec.Budget)
+		}
+		if stats.sampled {
+			lower, upper := stats.ScoreInterval()
+			fmt.Printf("The 95%% confidence interval of the mutation score is [%f, %f] since only a sample of the mutations has been executed\n", lower, upper)
+		}
+		if stats.equivalent > 0 {
+			fmt.Printf("%d mutations are equivalent since they compile to the same code as the original\n", stats.equivalent)
+		}
+		if stats.probablyEquivalent > 0 || stats.unreachable > 0 {
+			fmt.Printf("%d mutations are probably equivalent and %d mutations are unreachable according to the static analysis\n", stats.probablyEquivalent, stats.unreachable)
+		}
+		if stats.rescued > 0 {
+			fmt.Printf("%d mutations only compile because unused imports and variables have been fixed\n", stats.rescued)
+		}
+
+		if opts.Report.Dominators {
+			printDominators(os.Stdout, r)
+		}
 	} else {
 		fmt.Println("Cannot do a mutation testing summary since no exec command was executed.")
 	}
@@ -308,22 +612,35 @@ MUTATOR:
 	return returnOk
 }
 
-func mutate(opts *options, mutators []mutatorItem, mutationBlackList map[string]struct{}, mutationID int, pkg *types.Package, info *types.Info, file string, fset *token.FileSet, src ast.Node, node ast.Node, tmpFile string, execs []string, stats *mutationStats) int {
+func mutate(opts *options, mutators []mutatorItem, mutationBlackList map[string]struct{}, diff changedLines, mutationID int, pkg *types.Package, info *types.Info, file string, fset *token.FileSet, src *ast.File, node ast.Node, tmpFile string, mutants []*mutant, stats *mutationStats) (int, []*mutant) {
+	var statements []schemaStatement
+	if opts.Exec.Schemata {
+		statements = schemaStatements(node)
+	}
+
 	for _, m := range mutators {
 		debug(opts, "Mutator %s", m.Name)
 
-		changed := mutesting.MutateWalk(pkg, info, node, m.Mutator)
+		changed := mutesting.MutateWalkNodes(pkg, info, node, m.Mutator)
 
 		for {
-			_, ok := <-changed
+			changedNode, ok := <-changed
 
 			if !ok {
 				break
 			}
 
 			mutationFile := fmt.Sprintf("%s.%d", tmpFile, mutationID)
-			checksum, duplicate, err := saveAST(mutationBlackList, mutationFile, fset, src)
-			if err != nil {
+
+			// Imports and variables which are no longer used would not compile
+			resetFixes, fixes := astutil.FixUnused(info, src)
+			if fixes > 0 {
+				debug(opts, "Fixed %d unused imports and variables of %q", fixes, mutationFile)
+			}
+
+			if diff != nil && !diff.Intersects(file, fset.Position(changedNode.Pos).Line, fset.Position(changedNode.End).Line) {
+				debug(opts, "%q does not mutate any changed line, we ignore it", mutationFile)
+			} else if checksum, duplicate, err := saveAST(mutationBlackList, mutationFile, fset, src); err != nil {
 				fmt.Printf("INTERNAL ERROR %s\n", err.Error())
 			} else if duplicate {
 				debug(opts, "%q is a duplicate, we ignore it", mutationFile)
@@ -332,160 +649,482 @@ func mutate(opts *options, mutators []mutatorItem, mutationBlackList map[string]
 			} else {
 				debug(opts, "Save mutation into %q with checksum %s", mutationFile, checksum)
 
-				if !opts.Exec.NoExec {
-					execExitCode := mutateExec(opts, pkg, file, src, mutationFile, execs)
-
-					debug(opts, "Exited with %d", execExitCode)
-
-					msg := fmt.Sprintf("%q with checksum %s", mutationFile, checksum)
+				m := &mutant{
+					checksum:     checksum,
+					file:         file,
+					mutationFile: mutationFile,
+					originalFile: tmpFile + ".original",
+					mutator:      m.Name,
+					pkgPath:      pkg.Path(),
 
-					switch execExitCode {
-					case 0:
-						fmt.Printf("PASS %s\n", msg)
+					start: fset.Position(changedNode.Pos),
+					end:   fset.Position(changedNode.End),
 
-						stats.passed++
-					case 1:
-						fmt.Printf("FAIL %s\n", msg)
-
-						stats.failed++
-					case 2:
-						fmt.Printf("SKIP %s\n", msg)
+					fixed: fixes > 0,
+				}
 
-						stats.skipped++
-					default:
-						fmt.Printf("UNKOWN exit code for %s\n", msg)
+				if opts.Exec.Schemata {
+					m.schema, err = newSchemaSite(fset, statements, changedNode)
+					if err != nil {
+						fmt.Printf("INTERNAL ERROR %s\n", err.Error())
 					}
 				}
+
+				mutants = append(mutants, m)
 			}
 
-			changed <- true
+			resetFixes()
+
+			changed <- nil
 
 			// Ignore original state
 			<-changed
-			changed <- true
+			changed <- nil
 
 			mutationID++
 		}
 	}
 
-	return mutationID
+	return mutationID, mutants
 }
 
-func mutateExec(opts *options, pkg *types.Package, file string, src ast.Node, mutationFile string, execs []string) (execExitCode int) {
-	if len(execs) == 0 {
-		debug(opts, "Execute built-in exec command for mutation")
+// executeMutants executes the exec command for all given mutants using as many parallel workers as jobs are defined.
+// Mutants with a cached result are not executed again. The results of all executed mutants are cached if a cache is given.
+// If a deadline is given, no further mutants are executed as soon as a mutant would not finish before the deadline if it runs into its timeout.
+func executeMutants(opts *options, j *journal, c *resultCache, mutants []*mutant, execs []string, deadline time.Time, stats *mutationStats) {
+	queue := make(chan *mutant)
 
-		diff, err := exec.Command("diff", "-u", file, mutationFile).CombinedOutput()
-		if err == nil {
-			execExitCode = 0
-		} else if e, ok := err.(*exec.ExitError); ok {
-			execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
-		} else {
-			panic(err)
-		}
-		if execExitCode != 0 && execExitCode != 1 {
-			fmt.Printf("%s\n", diff)
+	var lock sync.Mutex
+	var wg sync.WaitGroup
 
-			panic("Could not execute diff on mutation file")
-		}
+	for i := uint(0); i < opts.Exec.Jobs; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for m := range queue {
+				if m.compileError != nil {
+					lock.Lock()
+
+					m.status = "COMPILE_ERROR"
+					fmt.Printf("%s %q with checksum %s\n", m.status, m.mutationFile, m.checksum)
+					if opts.General.Verbose {
+						fmt.Printf("Mutation did not compile: %v\n", m.compileError)
+					}
+
+					stats.compileErrors++
+
+					lock.Unlock()
+
+					continue
+				} else if m.equivalent {
+					lock.Lock()
+
+					m.status = "EQUIVALENT"
+					fmt.Printf("%s %q with checksum %s\n", m.status, m.mutationFile, m.checksum)
+
+					stats.equivalent++
+
+					lock.Unlock()
+
+					continue
+				} else if m.duplicateOf != nil {
+					lock.Lock()
+
+					m.status = "DUPLICATE"
+					fmt.Printf("%s %q with checksum %s compiles to the same code as %q\n", m.status, m.mutationFile, m.checksum, m.duplicateOf.mutationFile)
+
+					stats.duplicated++
+
+					lock.Unlock()
+
+					continue
+				} else if m.finding != nil {
+					lock.Lock()
+
+					m.status = m.finding.status
+					fmt.Printf("%s %q with checksum %s: %s\n", m.status, m.mutationFile, m.checksum, m.finding.reason)
+
+					if m.status == statusUnreachable {
+						stats.unreachable++
+					} else {
+						stats.probablyEquivalent++
+					}
 
-		defer func() {
-			_ = os.Rename(file+".tmp", file)
+					lock.Unlock()
+
+					continue
+				} else if m.notCovered {
+					lock.Lock()
+
+					m.status = "NOT_COVERED"
+					fmt.Printf("%s %q with checksum %s at %s:%d\n", m.status, m.mutationFile, m.checksum, m.file, m.start.Line)
+
+					stats.notCovered++
+
+					lock.Unlock()
+
+					continue
+				}
+
+				var out bytes.Buffer
+
+				var execExitCode int
+
+				if m.cached != nil {
+					execExitCode = m.cached.ExitCode
+					m.testResults = m.cached.Results
+
+					if opts.General.Debug {
+						fmt.Fprintln(&out, "Use the cached result of the mutation")
+					}
+
+					// Alive mutations are shown just as if they were executed
+					if execExitCode == 1 {
+						fmt.Fprintf(&out, "%s\n", mutationDiff(m))
+					}
+				} else {
+					execExitCode = mutateExec(opts, &out, j, m, execs)
+
+					if c != nil {
+						if err := c.Store(m, execExitCode); err != nil {
+							fmt.Fprintf(&out, "Could not cache the result of the mutation: %v\n", err)
+						}
+					}
+				}
+
+				lock.Lock()
+
+				_, _ = io.Copy(os.Stdout, &out)
+
+				debug(opts, "Exited with %d", execExitCode)
+
+				msg := fmt.Sprintf("%q with checksum %s", m.mutationFile, m.checksum)
+
+				switch execExitCode {
+				case 0:
+					m.status = "PASS"
+					fmt.Printf("PASS %s\n", msg)
+
+					stats.passed++
+				case 1:
+					m.status = "FAIL"
+					fmt.Printf("FAIL %s\n", msg)
+
+					stats.failed++
+				case 2:
+					m.status = "SKIP"
+					fmt.Printf("SKIP %s\n", msg)
+
+					stats.skipped++
+				case execTimeout:
+					m.status = "TIMEOUT"
+					fmt.Printf("TIMEOUT %s\n", msg)
+
+					stats.timedOut++
+				default:
+					m.status = "UNKNOWN"
+					fmt.Printf("UNKOWN exit code for %s\n", msg)
+				}
+
+				lock.Unlock()
+			}
 		}()
+	}
 
-		err = os.Rename(file, file+".tmp")
-		if err != nil {
-			panic(err)
+	exhausted := false
+
+	for _, m := range mutants {
+		// Mutants which are not executed are reported anyway since that takes no time
+		if m.executable() && m.cached == nil && !deadline.IsZero() {
+			exhausted = exhausted || time.Now().Add(m.timeout).After(deadline)
 		}
-		err = osutil.CopyFile(mutationFile, file)
-		if err != nil {
-			panic(err)
+
+		if exhausted && m.executable() && m.cached == nil {
+			lock.Lock()
+
+			m.status = "UNEXECUTED"
+			fmt.Printf("%s %q with checksum %s\n", m.status, m.mutationFile, m.checksum)
+
+			stats.unexecuted++
+
+			lock.Unlock()
+
+			continue
 		}
 
-		pkgName := pkg.Path()
-		if opts.Test.Recursive {
-			pkgName += "/..."
+		queue <- m
+	}
+	close(queue)
+
+	wg.Wait()
+}
+
+func mutateExec(opts *options, out io.Writer, j *journal, m *mutant, execs []string) (execExitCode int) {
+	overlayFile, err := writeOverlay(m)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(execs) == 0 {
+		if opts.General.Debug {
+			fmt.Fprintln(out, "Execute built-in exec command for mutation")
 		}
 
-		test, err := exec.Command("go", "test", "-timeout", fmt.Sprintf("%ds", opts.Exec.Timeout), pkgName).CombinedOutput()
-		if err == nil {
-			execExitCode = 0
-		} else if e, ok := err.(*exec.ExitError); ok {
-			execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
+		diff := mutationDiff(m)
+
+		var testCommand *exec.Cmd
+
+		if m.schemaBinary != "" {
+			// The test binary already contains the mutation which only needs to be activated
+			args := []string{"tool", "test2json", "-t", "-p", m.pkgPath, m.schemaBinary, "-test.v", "-test.timeout", m.timeout.String()}
+			if len(m.tests) > 0 {
+				args = append(args, "-test.run", testsPattern(m.tests))
+			}
+
+			testCommand = exec.Command("go", args...)
+			testCommand.Dir = filepath.Dir(m.file)
+			testCommand.Env = append(os.Environ(), fmt.Sprintf("%s=%d", schemataEnv, m.schemaID))
 		} else {
+			pkgName := m.pkgPath
+			if opts.Test.Recursive {
+				pkgName += "/..."
+			}
+
+			// The events of the tests tell which tests killed the mutation
+			args := []string{"test", "-json", "-timeout", m.timeout.String()}
+
+			if opts.Exec.InPlace {
+				err = j.Add(m.file, m.originalFile, m.mutationFile)
+				if err != nil {
+					panic(err)
+				}
+
+				defer func() {
+					_ = os.Rename(m.file+".tmp", m.file)
+
+					if err := j.Restore(m.mutationFile); err != nil {
+						panic(err)
+					}
+				}()
+
+				err = os.Rename(m.file, m.file+".tmp")
+				if err != nil {
+					panic(err)
+				}
+				err = osutil.CopyFile(m.mutationFile, m.file)
+				if err != nil {
+					panic(err)
+				}
+			} else {
+				// The original file is never touched since the mutation is only visible through the overlay
+				args = append(args, "-overlay", overlayFile)
+			}
+
+			if len(m.tests) > 0 {
+				args = append(args, "-run", testsPattern(m.tests))
+			}
+
+			testCommand = exec.Command("go", append(args, pkgName)...)
+		}
+
+		var test bytes.Buffer
+
+		testCommand.Stderr = &test
+		testCommand.Stdout = &test
+
+		var timedOut bool
+		execExitCode, timedOut, err = runCommand(testCommand, m.timeout)
+		if err != nil {
 			panic(err)
 		}
 
+		var testOutput []byte
+		var buildFailed bool
+		m.testResults, testOutput, buildFailed = parseTestEvents(&test)
+
 		if opts.General.Debug {
-			fmt.Printf("%s\n", test)
+			fmt.Fprintf(out, "%s\n", testOutput)
+		}
+
+		// Newer Go versions do not exit with 2 if the tests cannot be built
+		if buildFailed && execExitCode != 0 {
+			execExitCode = 2
+		}
+
+		if timedOut { // Tests did not finish in time -> TIMEOUT
+			if opts.General.Verbose {
+				fmt.Fprintln(out, "Mutation exceeded the timeout")
+			}
+
+			if opts.General.Debug {
+				fmt.Fprintf(out, "%s\n", diff)
+			}
+
+			return execTimeout
 		}
 
 		switch execExitCode {
 		case 0: // Tests passed -> FAIL
-			fmt.Printf("%s\n", diff)
+			fmt.Fprintf(out, "%s\n", diff)
 
 			execExitCode = 1
 		case 1: // Tests failed -> PASS
+			if failed := failedTests(m.testResults); opts.General.Verbose && len(failed) > 0 {
+				fmt.Fprintf(out, "Mutation was killed by %s\n", strings.Join(failed, ", "))
+			}
+
 			if opts.General.Debug {
-				fmt.Printf("%s\n", diff)
+				fmt.Fprintf(out, "%s\n", diff)
 			}
 
 			execExitCode = 0
 		case 2: // Did not compile -> SKIP
 			if opts.General.Verbose {
-				fmt.Println("Mutation did not compile")
+				fmt.Fprintln(out, "Mutation did not compile")
 			}
 
 			if opts.General.Debug {
-				fmt.Printf("%s\n", diff)
+				fmt.Fprintf(out, "%s\n", diff)
 			}
 		default: // Unknown exit code -> SKIP
-			fmt.Println("Unknown exit code")
-			fmt.Printf("%s\n", diff)
+			fmt.Fprintln(out, "Unknown exit code")
+			fmt.Fprintf(out, "%s\n", diff)
 		}
 
 		return execExitCode
 	}
 
-	debug(opts, "Execute %q for mutation", opts.Exec.Exec)
+	if opts.General.Debug {
+		fmt.Fprintf(out, "Execute %q for mutation\n", opts.Exec.Exec)
+	}
 
 	execCommand := exec.Command(execs[0], execs[1:]...)
 
-	execCommand.Stderr = os.Stderr
-	execCommand.Stdout = os.Stdout
+	execCommand.Stderr = out
+	execCommand.Stdout = out
 
 	execCommand.Env = append(os.Environ(), []string{
-		"MUTATE_CHANGED=" + mutationFile,
+		"MUTATE_CHANGED=" + m.mutationFile,
 		fmt.Sprintf("MUTATE_DEBUG=%t", opts.General.Debug),
-		"MUTATE_ORIGINAL=" + file,
-		"MUTATE_PACKAGE=" + pkg.Path(),
-		fmt.Sprintf("MUTATE_TIMEOUT=%d", opts.Exec.Timeout),
+		"MUTATE_ORIGINAL=" + m.file,
+		"MUTATE_OVERLAY=" + overlayFile,
+		"MUTATE_PACKAGE=" + m.pkgPath,
+		"MUTATE_TESTS=" + testsPattern(m.tests),
+		fmt.Sprintf("MUTATE_TIMEOUT=%d", int(math.Ceil(m.timeout.Seconds()))),
 		fmt.Sprintf("MUTATE_VERBOSE=%t", opts.General.Verbose),
 	}...)
 	if opts.Test.Recursive {
 		execCommand.Env = append(execCommand.Env, "TEST_RECURSIVE=true")
 	}
 
-	err := execCommand.Start()
+	// The exec command might replace the original file in place
+	err = j.Add(m.file, m.originalFile, m.mutationFile)
 	if err != nil {
 		panic(err)
 	}
+	defer func() {
+		if err := j.Restore(m.mutationFile); err != nil {
+			panic(err)
+		}
+	}()
 
-	// TODO timeout here
+	execExitCode, timedOut, err := runCommand(execCommand, m.timeout)
+	if err != nil {
+		panic(err)
+	}
 
-	err = execCommand.Wait()
+	if timedOut {
+		if opts.General.Verbose {
+			fmt.Fprintln(out, "Mutation exceeded the timeout")
+		}
 
-	if err == nil {
-		execExitCode = 0
-	} else if e, ok := err.(*exec.ExitError); ok {
-		execExitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
-	} else {
-		panic(err)
+		return execTimeout
 	}
 
 	return execExitCode
 }
 
+// mutationDiff returns the unified diff between the original file and the mutation of the given mutant.
+func mutationDiff(m *mutant) []byte {
+	diff, err := exec.Command("diff", "-u", m.file, m.mutationFile).CombinedOutput()
+
+	exitCode := 0
+	if e, ok := err.(*exec.ExitError); ok {
+		exitCode = e.Sys().(syscall.WaitStatus).ExitStatus()
+	} else if err != nil {
+		panic(err)
+	}
+	if exitCode != 0 && exitCode != 1 {
+		fmt.Printf("%s\n", diff)
+
+		panic("Could not execute diff on mutation file")
+	}
+
+	return diff
+}
+
+// writeOverlay writes an overlay file for the go command next to the mutation file which replaces the original file with the mutation.
+func writeOverlay(m *mutant) (string, error) {
+	return writeOverlayFile(m.file, m.mutationFile)
+}
+
+// writeOverlayFile writes an overlay next to the given replacement file which replaces the given file with it and returns the path of the overlay.
+func writeOverlayFile(file string, replacementFile string) (string, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	replacementFile, err = filepath.Abs(replacementFile)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(struct {
+		Replace map[string]string
+	}{
+		Replace: map[string]string{
+			file: replacementFile,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	overlayFile := replacementFile + ".json"
+
+	err = ioutil.WriteFile(overlayFile, data, 0666)
+	if err != nil {
+		return "", err
+	}
+
+	return overlayFile, nil
+}
+
+func restoreCmd() int {
+	j, err := openJournal(journalDirectory)
+	if err != nil {
+		return exitError("Could not open journal: %v", err)
+	}
+
+	if !j.Stale() {
+		fmt.Println("Nothing to restore")
+
+		return returnOk
+	}
+
+	restored, err := j.RestoreAll()
+	for _, file := range restored {
+		fmt.Printf("Restored %q\n", file)
+	}
+	if err != nil {
+		return exitError("Could not restore all original files: %v", err)
+	}
+
+	return returnOk
+}
+
 func main() {
 	os.Exit(mainCmd(os.Args[1:]))
 }
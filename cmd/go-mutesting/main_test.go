@@ -2,8 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,9 +18,19 @@ func TestMain(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec-timeout", "1"},
+		[]string{"--debug", "--exec-timeout", "10", "--no-cache"},
 		returnOk,
-		"The mutation score is 0.450000 (9 passed, 11 failed, 8 duplicated, 0 skipped, total is 20)",
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
+	)
+}
+
+func TestMainDerivedTimeout(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--no-cache"},
+		returnOk,
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
 	)
 }
 
@@ -23,9 +38,9 @@ func TestMainRecursive(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec-timeout", "1", "./..."},
+		[]string{"--debug", "--exec-timeout", "10", "--no-cache", "./..."},
 		returnOk,
-		"The mutation score is 0.476190 (10 passed, 11 failed, 8 duplicated, 0 skipped, total is 21)",
+		"The mutation score is 0.520000 (13 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 25)",
 	)
 }
 
@@ -33,9 +48,9 @@ func TestMainFromOtherDirectory(t *testing.T) {
 	testMain(
 		t,
 		"../..",
-		[]string{"--debug", "--exec-timeout", "1", "github.com/zimmski/go-mutesting/example"},
+		[]string{"--debug", "--exec-timeout", "10", "--no-cache", "github.com/zimmski/go-mutesting/example"},
 		returnOk,
-		"The mutation score is 0.450000 (9 passed, 11 failed, 8 duplicated, 0 skipped, total is 20)",
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
 	)
 }
 
@@ -43,13 +58,378 @@ func TestMainMatch(t *testing.T) {
 	testMain(
 		t,
 		"../../example",
-		[]string{"--debug", "--exec", "../scripts/exec/test-mutated-package.sh", "--exec-timeout", "1", "--match", "baz", "./..."},
+		[]string{"--debug", "--exec", "../scripts/exec/test-mutated-package.sh", "--exec-timeout", "10", "--no-cache", "--match", "baz", "./..."},
+		returnOk,
+		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 2)",
+	)
+}
+
+func TestMainJobs(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec-timeout", "10", "--no-cache", "--jobs", "4"},
+		returnOk,
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
+	)
+}
+
+func TestMainJobsExec(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec", "../scripts/exec/test-mutated-package.sh", "--no-cache", "--jobs", "2", "--match", "baz", "./..."},
+		returnError,
+		"Parallel jobs are only supported by the built-in exec command",
+	)
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec", "../scripts/exec/test-mutated-package.sh", "--exec-parallel", "--exec-timeout", "10", "--no-cache", "--jobs", "2", "--match", "baz", "./..."},
+		returnOk,
+		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 2)",
+	)
+}
+
+func TestMainInPlace(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec-timeout", "10", "--no-cache", "--exec-in-place"},
+		returnOk,
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
+	)
+}
+
+func TestMainSchemata(t *testing.T) {
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--verbose", "--exec-timeout", "10", "--no-cache", "--exec-schemata"},
+		returnOk,
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
+	)
+
+	assert.Contains(t, out, `Built 24 mutations of package "github.com/zimmski/go-mutesting/example"`)
+}
+
+func TestMainCoverage(t *testing.T) {
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec-timeout", "10", "--no-cache", "--coverage"},
 		returnOk,
-		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 skipped, total is 2)",
+		"The mutation score is 0.500000 (12 passed, 5 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 7 not covered, total is 24)",
 	)
 }
 
-func testMain(t *testing.T, root string, exec []string, expectedExitCode int, contains string) {
+func TestMainTestCovering(t *testing.T) {
+	defer useTemporaryCache(t)()
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec-timeout", "10", "--test-covering", "./..."},
+		returnOk,
+		"The mutation score is 0.520000 (13 passed, 6 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 6 not covered, total is 25)",
+	)
+}
+
+func TestMainCache(t *testing.T) {
+	defer useTemporaryCache(t)()
+
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec-timeout", "10"},
+		returnOk,
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
+	)
+	assert.NotContains(t, out, "Use the cached result of the mutation")
+
+	out = testMain(
+		t,
+		"../../example",
+		[]string{"--debug", "--exec-timeout", "10"},
+		returnOk,
+		"The mutation score is 0.500000 (12 passed, 12 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 24)",
+	)
+	assert.Equal(t, 24, strings.Count(out, "Use the cached result of the mutation"))
+}
+
+func TestMainReportJSON(t *testing.T) {
+	file, err := ioutil.TempFile("", "go-mutesting-report-")
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+	defer func() {
+		assert.Nil(t, os.Remove(file.Name()))
+	}()
+
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec-timeout", "10", "--no-cache", "--match", "baz", "--report-json", file.Name(), "./..."},
+		returnOk,
+		"The mutation score is 0.500000 (1 passed, 1 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 2)",
+	)
+
+	data, err := ioutil.ReadFile(file.Name())
+	assert.Nil(t, err)
+
+	var r report
+	assert.Nil(t, json.Unmarshal(data, &r))
+
+	assert.Equal(t, 2, r.Stats.Total)
+	if assert.Len(t, r.Mutants, 2) {
+		for _, m := range r.Mutants {
+			switch m.Status {
+			case "PASS":
+				assert.Equal(t, []string{"TestBaz"}, failedTests(m.Results))
+			case "FAIL":
+				assert.Empty(t, failedTests(m.Results))
+			default:
+				t.Errorf("unexpected status %q", m.Status)
+			}
+		}
+	}
+
+	assert.Equal(t, []reportTest{
+		{Package: "github.com/zimmski/go-mutesting/example", Test: "TestFoo", Executed: 1, Killed: 0, Score: 0, Minimal: false},
+		{Package: "github.com/zimmski/go-mutesting/example/sub", Test: "TestBaz", Executed: 1, Killed: 1, Score: 1, Minimal: true},
+	}, r.Tests)
+}
+
+func TestMainRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mutesting-restore-")
+	assert.Nil(t, err)
+	defer func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}()
+
+	file := filepath.Join(dir, "file.go")
+	assert.Nil(t, ioutil.WriteFile(file, []byte("package file\n"), 0644))
+
+	j, err := openJournal(filepath.Join(dir, journalDirectory))
+	assert.Nil(t, err)
+	assert.Nil(t, j.Add(file, file, "file.go.0"))
+	assert.Nil(t, ioutil.WriteFile(file, []byte("package mutation\n"), 0644))
+
+	testMain(
+		t,
+		dir,
+		[]string{"--exec-timeout", "10", "file.go"},
+		returnError,
+		"Found the journal",
+	)
+
+	testMain(
+		t,
+		dir,
+		[]string{"restore"},
+		returnOk,
+		"Restored",
+	)
+
+	data, err := ioutil.ReadFile(file)
+	assert.Nil(t, err)
+	assert.Equal(t, "package file\n", string(data))
+}
+
+func TestMainBaselineFails(t *testing.T) {
+	dir, removeModule := writeModule(
+		t,
+		"baseline",
+		"package baseline\n\nfunc Answer(a int) int {\n\tif a > 0 {\n\t\treturn 42\n\t}\n\n\treturn 0\n}\n",
+		"package baseline\n\nimport \"testing\"\n\nfunc TestAnswer(t *testing.T) {\n\tif Answer(1) != 41 {\n\t\tt.Fail()\n\t}\n}\n",
+	)
+	defer removeModule()
+
+	testMain(
+		t,
+		dir,
+		[]string{"baseline.go"},
+		returnError,
+		"fail without any mutation",
+	)
+}
+
+// useTemporaryCache lets go-mutesting use a temporary cache directory instead of the cache directory of the user and returns a function which removes it again.
+func useTemporaryCache(t *testing.T) func() {
+	cacheDir, err := ioutil.TempDir("", "go-mutesting-cache-")
+	assert.Nil(t, err)
+
+	// Keep the build cache of Go which is in the same directory by default
+	goCache, err := exec.Command("go", "env", "GOCACHE").Output()
+	assert.Nil(t, err)
+	saveGoCache, hasGoCache := os.LookupEnv("GOCACHE")
+	assert.Nil(t, os.Setenv("GOCACHE", strings.TrimSpace(string(goCache))))
+	saveCacheHome := os.Getenv("XDG_CACHE_HOME")
+	assert.Nil(t, os.Setenv("XDG_CACHE_HOME", cacheDir))
+
+	return func() {
+		if hasGoCache {
+			assert.Nil(t, os.Setenv("GOCACHE", saveGoCache))
+		} else {
+			assert.Nil(t, os.Unsetenv("GOCACHE"))
+		}
+		assert.Nil(t, os.Setenv("XDG_CACHE_HOME", saveCacheHome))
+
+		assert.Nil(t, os.RemoveAll(cacheDir))
+	}
+}
+
+// writeModule writes a module with the given name into a temporary directory whose only package consists of the given source and test source, and returns the directory and a function which removes it again.
+func writeModule(t *testing.T, name string, source string, testSource string) (string, func()) {
+	dir, err := ioutil.TempDir("", "go-mutesting-"+name+"-")
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module "+name+"\n"), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+".go"), []byte(source), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name+"_test.go"), []byte(testSource), 0644))
+
+	return dir, func() {
+		assert.Nil(t, os.RemoveAll(dir))
+	}
+}
+
+// git executes git with the given arguments in the given directory. Commits have a fixed author and date.
+func git(t *testing.T, dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=go-mutesting", "-c", "user.email=go-mutesting@example.com"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE=2020-01-01T00:00:00Z", "GIT_COMMITTER_DATE=2020-01-01T00:00:00Z")
+	out, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(out))
+}
+
+func TestMainDiff(t *testing.T) {
+	dir, removeModule := writeModule(
+		t,
+		"diff",
+		"package diff\n\nfunc Abs(a int) int {\n\tif a < 0 {\n\t\treturn -a\n\t}\n\n\treturn a\n}\n\nfunc Max(a int, b int) int {\n\tif a > b {\n\t\treturn a\n\t}\n\n\treturn b\n}\n",
+		"package diff\n\nimport \"testing\"\n\nfunc TestAbs(t *testing.T) {\n\tif Abs(-1) != 1 || Max(1, 2) != 2 || Max(2, 1) != 2 {\n\t\tt.Fail()\n\t}\n}\n",
+	)
+	defer removeModule()
+
+	git(t, dir, "init")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-m", "Initial commit")
+
+	// The configuration of the user must not change the paths of the diff
+	git(t, dir, "config", "diff.mnemonicPrefix", "true")
+
+	// Only the mutations of the changed comparison are executed
+	data, err := ioutil.ReadFile(filepath.Join(dir, "diff.go"))
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "diff.go"), []byte(strings.Replace(string(data), "a > b", "b < a", 1)), 0644))
+
+	testMain(
+		t,
+		dir,
+		[]string{"--exec-timeout", "10", "--no-cache", "--diff", "HEAD", "diff.go"},
+		returnOk,
+		"The mutation score is 0.750000 (3 passed, 1 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 4)",
+	)
+
+	git(t, dir, "add", "diff.go")
+
+	testMain(
+		t,
+		dir,
+		[]string{"--exec-timeout", "10", "--no-cache", "--staged", "diff.go"},
+		returnOk,
+		"The mutation score is 0.750000 (3 passed, 1 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 4)",
+	)
+}
+
+func TestMainCompileError(t *testing.T) {
+	dir, removeModule := writeModule(
+		t,
+		"sign",
+		"package sign\n\nfunc Sign(a int) int {\n\tif a < 0 {\n\t\treturn -1\n\t} else {\n\t\treturn 1\n\t}\n}\n",
+		"package sign\n\nimport \"testing\"\n\nfunc TestSign(t *testing.T) {\n\tif Sign(-2) != -1 || Sign(2) != 1 {\n\t\tt.Fail()\n\t}\n}\n",
+	)
+	defer removeModule()
+
+	// Removing either branch leads to a missing return
+	out := testMain(
+		t,
+		dir,
+		[]string{"--exec-timeout", "10", "--no-cache", "sign.go"},
+		returnOk,
+		"The mutation score is 0.000000 (0 passed, 1 failed, 0 duplicated, 2 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 1)",
+	)
+	assert.Equal(t, 2, strings.Count(out, "COMPILE_ERROR"))
+}
+
+func TestMainFixUnused(t *testing.T) {
+	dir, removeModule := writeModule(
+		t,
+		"both",
+		"package both\n\nfunc Both(x int, y int) bool {\n\tpositive := x > 0\n\n\treturn positive && y > 0\n}\n",
+		"package both\n\nimport \"testing\"\n\nfunc TestBoth(t *testing.T) {\n\tif !Both(1, 1) || Both(-1, 1) {\n\t\tt.Fail()\n\t}\n}\n",
+	)
+	defer removeModule()
+
+	// Removing the only use of the variable does not lead to a compile error
+	testMain(
+		t,
+		dir,
+		[]string{"--exec-timeout", "10", "--no-cache", "both.go"},
+		returnOk,
+		"The mutation score is 0.333333 (2 passed, 4 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 6)\n1 mutations only compile because unused imports and variables have been fixed",
+	)
+}
+
+func TestMainEquivalence(t *testing.T) {
+	// Removing the dead composite literal of a.go compiles to the same code, and two mutations of example.go compile to the same code
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--exec-timeout", "10", "--no-cache", "--equivalence"},
+		returnOk,
+		"The mutation score is 0.500000 (11 passed, 11 failed, 9 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 22)\n1 mutations are equivalent since they compile to the same code as the original",
+	)
+	assert.Equal(t, 1, strings.Count(out, "EQUIVALENT"))
+	assert.Equal(t, 1, strings.Count(out, "DUPLICATE"))
+}
+
+func TestMainStaticAnalysis(t *testing.T) {
+	dir, removeModule := writeModule(t, "dead", deadSource, deadTestSource)
+	defer removeModule()
+
+	out := testMain(
+		t,
+		dir,
+		[]string{"--exec-timeout", "10", "--no-cache", "--static-analysis", "dead.go"},
+		returnOk,
+		"The mutation score is 0.800000 (4 passed, 1 failed, 0 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 5)\n3 mutations are probably equivalent and 2 mutations are unreachable according to the static analysis",
+	)
+	assert.Contains(t, out, "the value assigned to positive is never used")
+}
+
+func TestMainSample(t *testing.T) {
+	out := testMain(
+		t,
+		"../../example",
+		[]string{"--exec-timeout", "10", "--no-cache", "--sample", "50%", "--seed", "1", "--sample-stratify", "mutator"},
+		returnOk,
+		"Sampled 12 of 24 mutations with the seed 1",
+	)
+	assert.Contains(t, out, "The mutation score is 0.666667 (8 passed, 4 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 12)\nThe 95% confidence interval of the mutation score is [0.390618, 0.861882]")
+}
+
+func TestMainBudget(t *testing.T) {
+	// No mutation can finish within its timeout before the budget is used up
+	testMain(
+		t,
+		"../../example",
+		[]string{"--exec-timeout", "10", "--no-cache", "--budget", "1s"},
+		returnOk,
+		"The mutation score is 0.000000 (0 passed, 0 failed, 8 duplicated, 0 compile errors, 0 skipped, 0 timed out, 0 not covered, total is 0)\n24 mutations have not been executed since the time budget of 1s has been used up",
+	)
+}
+
+func testMain(t *testing.T, root string, exec []string, expectedExitCode int, contains string) string {
 	saveStderr := os.Stderr
 	saveStdout := os.Stdout
 	saveCwd, err := os.Getwd()
@@ -85,4 +465,6 @@ func testMain(t *testing.T, root string, exec []string, expectedExitCode int, co
 
 	assert.Equal(t, expectedExitCode, exitCode)
 	assert.Contains(t, out, contains)
+
+	return out
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// runningCommands holds all commands which are currently executed, so they can be killed if we get interrupted.
+var runningCommands = struct {
+	sync.Mutex
+
+	commands map[*exec.Cmd]struct{}
+}{
+	commands: map[*exec.Cmd]struct{}{},
+}
+
+// waitDelay is the time which is waited for the output of a command after it exited.
+// Processes which left the process group of the command, e.g. with setsid, cannot be killed and might hold on to its output forever.
+const waitDelay = 5 * time.Second
+
+// runCommand executes the given command and returns its exit code.
+// The command and all its child processes are killed if the command does not exit within the given timeout. A timeout of zero means that the command is never killed.
+func runCommand(cmd *exec.Cmd, timeout time.Duration) (exitCode int, timedOut bool, err error) {
+	setProcessGroup(cmd)
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = waitDelay
+	}
+
+	runningCommands.Lock()
+	err = cmd.Start()
+	if err != nil {
+		runningCommands.Unlock()
+
+		return 0, false, err
+	}
+	runningCommands.commands[cmd] = struct{}{}
+	runningCommands.Unlock()
+
+	var lock sync.Mutex
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			lock.Lock()
+			timedOut = true
+			lock.Unlock()
+
+			_ = killProcessGroup(cmd)
+		})
+		defer timer.Stop()
+	}
+
+	err = cmd.Wait()
+
+	runningCommands.Lock()
+	delete(runningCommands.commands, cmd)
+	runningCommands.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if timedOut {
+		return 0, true, nil
+	} else if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		return 0, false, nil
+	} else if e, ok := err.(*exec.ExitError); ok {
+		return e.Sys().(syscall.WaitStatus).ExitStatus(), false, nil
+	}
+
+	return 0, false, err
+}
+
+// killRunningCommands kills all currently executed commands and their child processes.
+func killRunningCommands() {
+	runningCommands.Lock()
+	defer runningCommands.Unlock()
+
+	for cmd := range runningCommands.commands {
+		_ = killProcessGroup(cmd)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCommand(t *testing.T) {
+	exitCode, timedOut, err := runCommand(exec.Command("sh", "-c", "exit 3"), time.Minute)
+	assert.Nil(t, err)
+	assert.False(t, timedOut)
+	assert.Equal(t, 3, exitCode)
+
+	// The background process holds on to the output, so waiting for the command only finishes if the whole process group is killed
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", "sleep 60 & sleep 60")
+	cmd.Stdout = &out
+
+	start := time.Now()
+	_, timedOut, err = runCommand(cmd, time.Second)
+	assert.Nil(t, err)
+	assert.True(t, timedOut)
+	assert.True(t, time.Since(start) < 30*time.Second)
+
+	// A process which left the process group cannot be killed, but it does not keep the timeout from returning
+	out.Reset()
+	cmd = exec.Command("sh", "-c", "setsid sleep 60 & sleep 60")
+	cmd.Stdout = &out
+
+	start = time.Now()
+	_, timedOut, err = runCommand(cmd, time.Second)
+	assert.Nil(t, err)
+	assert.True(t, timedOut)
+	assert.True(t, time.Since(start) < 30*time.Second)
+}
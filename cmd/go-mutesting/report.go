@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// reportMutant holds the result of one mutation in a report.
+type reportMutant struct {
+	Checksum     string
+	File         string
+	Line         int
+	MutationFile string
+	Package      string
+	Status       string
+	// Reason holds why the static analysis found that the mutation cannot be killed.
+	Reason string
+	// Tests holds the tests which have been selected for the mutation, or nil if all tests were executed.
+	Tests   []string
+	Results []testResult
+	// Dominator is true if the mutation is part of the minimal set of mutations which subsumes all other mutations.
+	Dominator bool
+}
+
+// reportStats holds the summary of all mutations in a report.
+type reportStats struct {
+	Passed        int
+	Failed        int
+	Duplicated    int
+	CompileErrors int
+	// Equivalent is the number of mutations which compile to the same code as the original.
+	Equivalent int
+	// ProbablyEquivalent and Unreachable are the numbers of mutations which the static analysis found to be not killable.
+	ProbablyEquivalent int
+	Unreachable        int
+	// Rescued is the number of mutations which only compile because unused imports and variables have been fixed.
+	Rescued    int
+	Skipped    int
+	TimedOut   int
+	NotCovered int
+	// Unexecuted is the number of mutations which have not been executed since the time budget has been used up.
+	Unexecuted int
+	Total      int
+	Score      float64
+	// ScoreLower and ScoreUpper bound the 95% confidence interval of the score if only a sample of the mutations has been executed, otherwise they equal the score.
+	ScoreLower float64
+	ScoreUpper float64
+
+	// Dominators is the number of dominator mutations of which KilledDominators have been killed.
+	Dominators       int
+	KilledDominators int
+	// DominatorScore is the ratio of killed dominator mutations to all dominator mutations.
+	DominatorScore float64
+}
+
+// reportTest holds the effectiveness of one top-level test in a report.
+type reportTest struct {
+	Package string
+	Test    string
+	// Executed is the number of mutations for which the test was executed.
+	Executed int
+	// Killed is the number of mutations which were killed by the test.
+	Killed int
+	// Score is the ratio of killed mutations to executed mutations.
+	Score float64
+	// Minimal is true if the test is part of the minimal set of tests which kills all killed mutations.
+	Minimal bool
+}
+
+// report holds the results of all executed mutations.
+type report struct {
+	Mutants []reportMutant
+	Stats   reportStats
+	Tests   []reportTest
+}
+
+// newReport creates a report of the given executed mutations.
+func newReport(mutants []*mutant, stats *mutationStats) *report {
+	r := &report{
+		Mutants: make([]reportMutant, 0, len(mutants)),
+		Stats: reportStats{
+			Passed:             stats.passed,
+			Failed:             stats.failed,
+			Duplicated:         stats.duplicated,
+			CompileErrors:      stats.compileErrors,
+			Equivalent:         stats.equivalent,
+			ProbablyEquivalent: stats.probablyEquivalent,
+			Unreachable:        stats.unreachable,
+			Rescued:            stats.rescued,
+			Skipped:            stats.skipped,
+			TimedOut:           stats.timedOut,
+			NotCovered:         stats.notCovered,
+			Unexecuted:         stats.unexecuted,
+			Total:              stats.Total(),
+			Score:              stats.Score(),
+		},
+	}
+
+	if stats.sampled {
+		r.Stats.ScoreLower, r.Stats.ScoreUpper = stats.ScoreInterval()
+	} else {
+		r.Stats.ScoreLower, r.Stats.ScoreUpper = r.Stats.Score, r.Stats.Score
+	}
+
+	for _, m := range mutants {
+		var reason string
+		if m.finding != nil {
+			reason = m.finding.reason
+		}
+
+		r.Mutants = append(r.Mutants, reportMutant{
+			Checksum:     m.checksum,
+			File:         m.file,
+			Line:         m.start.Line,
+			MutationFile: m.mutationFile,
+			Package:      m.pkgPath,
+			Status:       m.status,
+			Reason:       reason,
+			Tests:        m.tests,
+			Results:      m.testResults,
+		})
+	}
+
+	r.Tests = testEffectiveness(r.Mutants)
+
+	markDominators(r.Mutants)
+	for _, m := range r.Mutants {
+		if !m.Dominator {
+			continue
+		}
+
+		r.Stats.Dominators++
+		if killedStatus(m.Status) {
+			r.Stats.KilledDominators++
+		}
+	}
+	if r.Stats.Dominators > 0 {
+		r.Stats.DominatorScore = float64(r.Stats.KilledDominators) / float64(r.Stats.Dominators)
+	}
+
+	return r
+}
+
+// killedStatus returns true if the given status states that the mutation was killed.
+func killedStatus(status string) bool {
+	return status == "PASS" || status == "TIMEOUT"
+}
+
+// markDominators marks the minimal set of mutations which subsumes all other mutations.
+// A killed mutation subsumes another mutation if every test that kills it also kills the other mutation. Mutations which are killed by the same tests subsume each other, so only one of them is a dominator. Mutations which are alive or whose killing tests are unknown cannot be subsumed and are therefore always dominators.
+func markDominators(mutants []reportMutant) {
+	var classes []map[string]struct{}
+	var representatives []int
+
+CLASSES:
+	for i, m := range mutants {
+		// Skipped mutations are not part of any score
+		if !killedStatus(m.Status) && m.Status != "FAIL" && m.Status != "NOT_COVERED" {
+			continue
+		}
+
+		kills := map[string]struct{}{}
+		if m.Status == "PASS" {
+			for _, result := range m.Results {
+				// Tests of different packages can have the same name
+				if result.Action == "fail" && !strings.Contains(result.Test, "/") {
+					kills[result.Package+"."+result.Test] = struct{}{}
+				}
+			}
+		}
+
+		if len(kills) == 0 {
+			mutants[i].Dominator = true
+
+			continue
+		}
+
+		for _, c := range classes {
+			if subset(c, kills) && subset(kills, c) {
+				continue CLASSES
+			}
+		}
+
+		classes = append(classes, kills)
+		representatives = append(representatives, i)
+	}
+
+	// Only mutations whose killing tests are not a strict superset of the killing tests of another mutation are dominators
+	for c, kills := range classes {
+		dominator := true
+
+		for o, other := range classes {
+			if o != c && subset(other, kills) {
+				dominator = false
+
+				break
+			}
+		}
+
+		if dominator {
+			mutants[representatives[c]].Dominator = true
+		}
+	}
+}
+
+// subset returns true if every element of a is an element of b.
+func subset(a map[string]struct{}, b map[string]struct{}) bool {
+	for e := range a {
+		if _, ok := b[e]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// testEffectiveness returns the effectiveness of every top-level test which was executed for the given mutations.
+func testEffectiveness(mutants []reportMutant) []reportTest {
+	index := map[string]int{}
+	var tests []reportTest
+	var kills []map[int]struct{}
+
+	for i, m := range mutants {
+		for _, result := range m.Results {
+			// Subtests are already part of their top-level test
+			if strings.Contains(result.Test, "/") {
+				continue
+			}
+
+			key := result.Package + "." + result.Test
+
+			t, ok := index[key]
+			if !ok {
+				t = len(tests)
+				index[key] = t
+
+				tests = append(tests, reportTest{
+					Package: result.Package,
+					Test:    result.Test,
+				})
+				kills = append(kills, map[int]struct{}{})
+			}
+
+			tests[t].Executed++
+
+			if m.Status == "PASS" && result.Action == "fail" {
+				tests[t].Killed++
+				kills[t][i] = struct{}{}
+			}
+		}
+	}
+
+	for t := range tests {
+		tests[t].Score = float64(tests[t].Killed) / float64(tests[t].Executed)
+	}
+
+	// Greedily choose the test which kills the most mutations that are not yet killed until all killed mutations are covered
+	killed := map[int]struct{}{}
+	for {
+		best := -1
+		bestCount := 0
+
+		for t := range tests {
+			if tests[t].Minimal {
+				continue
+			}
+
+			count := 0
+			for i := range kills[t] {
+				if _, ok := killed[i]; !ok {
+					count++
+				}
+			}
+
+			if count > bestCount || (count == bestCount && count > 0 && lessTest(tests[t], tests[best])) {
+				best = t
+				bestCount = count
+			}
+		}
+
+		if best == -1 {
+			break
+		}
+
+		tests[best].Minimal = true
+		for i := range kills[best] {
+			killed[i] = struct{}{}
+		}
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		return lessTest(tests[i], tests[j])
+	})
+
+	return tests
+}
+
+func lessTest(a reportTest, b reportTest) bool {
+	if a.Package != b.Package {
+		return a.Package < b.Package
+	}
+
+	return a.Test < b.Test
+}
+
+// printKillMatrix prints the effectiveness of all tests of the given report.
+func printKillMatrix(w io.Writer, r *report) {
+	fmt.Fprintln(w, "Effectiveness of the tests:")
+	for _, t := range r.Tests {
+		fmt.Fprintf(w, "\t%s.%s killed %d of %d executed mutations (score %f)\n", t.Package, t.Test, t.Killed, t.Executed, t.Score)
+	}
+
+	fmt.Fprintln(w, "Tests which never killed a mutation:")
+	for _, t := range r.Tests {
+		if t.Killed == 0 {
+			fmt.Fprintf(w, "\t%s.%s\n", t.Package, t.Test)
+		}
+	}
+
+	fmt.Fprintln(w, "Minimal set of tests which kills all killed mutations:")
+	for _, t := range r.Tests {
+		if t.Minimal {
+			fmt.Fprintf(w, "\t%s.%s\n", t.Package, t.Test)
+		}
+	}
+}
+
+// printDominators prints the dominator mutations and the dominator score of the given report.
+func printDominators(w io.Writer, r *report) {
+	fmt.Fprintln(w, "Dominator mutations:")
+	for _, m := range r.Mutants {
+		if m.Dominator {
+			fmt.Fprintf(w, "\t%s %q with checksum %s\n", m.Status, m.MutationFile, m.Checksum)
+		}
+	}
+
+	fmt.Fprintf(w, "The dominator score is %f (%d killed, %d alive, total is %d)\n", r.Stats.DominatorScore, r.Stats.KilledDominators, r.Stats.Dominators-r.Stats.KilledDominators, r.Stats.Dominators)
+}
+
+// writeJSONReport writes the given report as JSON to the given file.
+func writeJSONReport(file string, r *report) error {
+	data, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, data, 0644)
+}
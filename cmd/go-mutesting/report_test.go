@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestEffectiveness(t *testing.T) {
+	results := func(failed ...string) []testResult {
+		var l []testResult
+
+		for _, test := range []string{"TestA", "TestB", "TestC", "TestD"} {
+			action := "pass"
+			for _, f := range failed {
+				if f == test {
+					action = "fail"
+				}
+			}
+
+			l = append(l, testResult{Package: "example", Test: test, Action: action})
+		}
+
+		return l
+	}
+
+	r := &report{
+		Mutants: []reportMutant{
+			{Status: "PASS", Results: results("TestA")},
+			{Status: "PASS", Results: results("TestA", "TestB")},
+			{Status: "PASS", Results: results("TestC")},
+			{Status: "FAIL", Results: results()},
+		},
+	}
+	r.Tests = testEffectiveness(r.Mutants)
+
+	assert.Equal(t, []reportTest{
+		{Package: "example", Test: "TestA", Executed: 4, Killed: 2, Score: 0.5, Minimal: true},
+		{Package: "example", Test: "TestB", Executed: 4, Killed: 1, Score: 0.25},
+		{Package: "example", Test: "TestC", Executed: 4, Killed: 1, Score: 0.25, Minimal: true},
+		{Package: "example", Test: "TestD", Executed: 4, Killed: 0, Score: 0},
+	}, r.Tests)
+
+	var out bytes.Buffer
+	printKillMatrix(&out, r)
+
+	assert.Equal(t, `Effectiveness of the tests:
+	example.TestA killed 2 of 4 executed mutations (score 0.500000)
+	example.TestB killed 1 of 4 executed mutations (score 0.250000)
+	example.TestC killed 1 of 4 executed mutations (score 0.250000)
+	example.TestD killed 0 of 4 executed mutations (score 0.000000)
+Tests which never killed a mutation:
+	example.TestD
+Minimal set of tests which kills all killed mutations:
+	example.TestA
+	example.TestC
+`, out.String())
+}
+
+func TestMarkDominators(t *testing.T) {
+	results := func(failed ...string) []testResult {
+		var l []testResult
+
+		for _, test := range failed {
+			l = append(l, testResult{Package: "example", Test: test, Action: "fail"})
+		}
+
+		return l
+	}
+
+	r := newReport([]*mutant{
+		{status: "PASS", testResults: results("TestA")},
+		{status: "PASS", testResults: results("TestA", "TestB")},
+		{status: "PASS", testResults: results("TestA")},
+		{status: "PASS", testResults: results("TestB")},
+		{status: "FAIL"},
+		{status: "SKIP"},
+		{status: "TIMEOUT"},
+	}, &mutationStats{})
+
+	var dominators []bool
+	for _, m := range r.Mutants {
+		dominators = append(dominators, m.Dominator)
+	}
+
+	assert.Equal(t, []bool{true, false, false, true, true, false, true}, dominators)
+	assert.Equal(t, 4, r.Stats.Dominators)
+	assert.Equal(t, 3, r.Stats.KilledDominators)
+	assert.Equal(t, 0.75, r.Stats.DominatorScore)
+
+	// Tests of different packages with the same name are different tests
+	r = newReport([]*mutant{
+		{status: "PASS", testResults: []testResult{{Package: "a", Test: "TestParse", Action: "fail"}}},
+		{status: "PASS", testResults: []testResult{{Package: "b", Test: "TestParse", Action: "fail"}}},
+		{status: "PASS", testResults: []testResult{{Package: "a", Test: "TestParse", Action: "fail"}, {Package: "b", Test: "TestParse", Action: "fail"}}},
+	}, &mutationStats{})
+
+	dominators = nil
+	for _, m := range r.Mutants {
+		dominators = append(dominators, m.Dominator)
+	}
+
+	assert.Equal(t, []bool{true, true, false}, dominators)
+	assert.Equal(t, 2, r.Stats.Dominators)
+}
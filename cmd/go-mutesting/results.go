@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// testEvent holds one event of the output of "go test -json".
+type testEvent struct {
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64
+	Output      string
+	FailedBuild string
+}
+
+// testResult holds the outcome of one test which was executed for a mutation.
+type testResult struct {
+	Package string
+	Test    string
+	// Action is the final action of the test which is either "pass", "fail" or "skip".
+	Action string
+	// Elapsed holds the seconds the test took.
+	Elapsed float64
+}
+
+// parseTestEvents parses the output of "go test -json" and returns the outcome of every executed test, the plain output of the tests and if the tests could not be built.
+// Lines which are not events, e.g. compiler errors of older Go versions, are part of the plain output.
+func parseTestEvents(r io.Reader) (results []testResult, output []byte, buildFailed bool) {
+	var out bytes.Buffer
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for s.Scan() {
+		line := s.Bytes()
+
+		var e testEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &e) != nil {
+			out.Write(line)
+			out.WriteByte('\n')
+
+			if bytes.Contains(line, []byte("[build failed]")) || bytes.Contains(line, []byte("[setup failed]")) {
+				buildFailed = true
+			}
+
+			continue
+		}
+
+		switch e.Action {
+		case "output", "build-output":
+			out.WriteString(e.Output)
+
+			if strings.Contains(e.Output, "[build failed]") || strings.Contains(e.Output, "[setup failed]") {
+				buildFailed = true
+			}
+		case "pass", "fail", "skip":
+			if e.Test != "" {
+				results = append(results, testResult{
+					Package: e.Package,
+					Test:    e.Test,
+					Action:  e.Action,
+					Elapsed: e.Elapsed,
+				})
+			} else if e.FailedBuild != "" {
+				buildFailed = true
+			}
+		}
+	}
+
+	return results, out.Bytes(), buildFailed
+}
+
+// failedTests returns the top-level tests of the given results which failed.
+func failedTests(results []testResult) []string {
+	var tests []string
+
+	for _, r := range results {
+		// Subtests are already part of their top-level test
+		if r.Action == "fail" && !strings.Contains(r.Test, "/") {
+			tests = append(tests, r.Test)
+		}
+	}
+
+	return tests
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTestEvents(t *testing.T) {
+	results, output, buildFailed := parseTestEvents(strings.NewReader(`{"Action":"start","Package":"example"}
+{"Action":"run","Package":"example","Test":"TestA"}
+{"Action":"output","Package":"example","Test":"TestA","Output":"=== RUN   TestA\n"}
+{"Action":"pass","Package":"example","Test":"TestA","Elapsed":0.5}
+{"Action":"run","Package":"example","Test":"TestB"}
+{"Action":"run","Package":"example","Test":"TestB/sub"}
+{"Action":"fail","Package":"example","Test":"TestB/sub","Elapsed":0.1}
+{"Action":"fail","Package":"example","Test":"TestB","Elapsed":0.25}
+{"Action":"output","Package":"example","Output":"FAIL\n"}
+{"Action":"fail","Package":"example","Elapsed":1}
+`))
+	assert.Equal(t, []testResult{
+		{Package: "example", Test: "TestA", Action: "pass", Elapsed: 0.5},
+		{Package: "example", Test: "TestB/sub", Action: "fail", Elapsed: 0.1},
+		{Package: "example", Test: "TestB", Action: "fail", Elapsed: 0.25},
+	}, results)
+	assert.Equal(t, "=== RUN   TestA\nFAIL\n", string(output))
+	assert.False(t, buildFailed)
+	assert.Equal(t, []string{"TestB"}, failedTests(results))
+
+	results, _, buildFailed = parseTestEvents(strings.NewReader(`{"ImportPath":"example [example.test]","Action":"build-output","Output":"# example [example.test]\n"}
+{"ImportPath":"example [example.test]","Action":"build-fail"}
+{"Action":"start","Package":"example"}
+{"Action":"fail","Package":"example","Elapsed":0,"FailedBuild":"example [example.test]"}
+`))
+	assert.Empty(t, results)
+	assert.True(t, buildFailed)
+
+	// Older Go versions print build failures as plain text
+	_, output, buildFailed = parseTestEvents(strings.NewReader("# example\n./example.go:3:23: undefined: x\nFAIL\texample [build failed]\n"))
+	assert.Equal(t, "# example\n./example.go:3:23: undefined: x\nFAIL\texample [build failed]\n", string(output))
+	assert.True(t, buildFailed)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseSampleSize parses a sample size which is either a number of mutations, e.g. "500", or a percentage of all mutations, e.g. "20%".
+func parseSampleSize(size string) (count int, percentage float64, err error) {
+	if strings.HasSuffix(size, "%") {
+		percentage, err = strconv.ParseFloat(strings.TrimSuffix(size, "%"), 64)
+		if err != nil || percentage <= 0 || percentage > 100 {
+			return 0, 0, fmt.Errorf("%q is not a percentage between 0 and 100", size)
+		}
+
+		return 0, percentage, nil
+	}
+
+	count, err = strconv.Atoi(size)
+	if err != nil || count <= 0 {
+		return 0, 0, fmt.Errorf("%q is not a positive number of mutations", size)
+	}
+
+	return count, 0, nil
+}
+
+// sampleMutants returns a random sample of the given mutants whose size is given by the sample option and which is reproducible with the given seed.
+// If the mutants are stratified by mutator or package, every stratum is sampled on its own with a size proportional to its share of all mutants but at least one mutant, so that small strata are not starved.
+func sampleMutants(opts *options, mutants []*mutant, seed int64) []*mutant {
+	count, percentage, err := parseSampleSize(opts.Filter.Sample)
+	if err != nil {
+		panic(err)
+	}
+	if percentage > 0 {
+		count = int(math.Ceil(percentage / 100 * float64(len(mutants))))
+	}
+	if count >= len(mutants) {
+		return mutants
+	}
+
+	var keys []string
+	strata := map[string][]int{}
+
+	for i, m := range mutants {
+		var key []string
+		for _, s := range opts.Filter.Stratify {
+			switch s {
+			case "mutator":
+				key = append(key, m.mutator)
+			case "package":
+				key = append(key, m.pkgPath)
+			}
+		}
+
+		k := strings.Join(key, "\x00")
+		if _, ok := strata[k]; !ok {
+			keys = append(keys, k)
+		}
+		strata[k] = append(strata[k], i)
+	}
+
+	sizes := make([]int, len(keys))
+	remainders := make([]float64, len(keys))
+	assigned := 0
+
+	for i, k := range keys {
+		exact := float64(count) * float64(len(strata[k])) / float64(len(mutants))
+
+		sizes[i] = int(exact)
+		remainders[i] = exact - float64(sizes[i])
+		if sizes[i] == 0 {
+			sizes[i] = 1
+			remainders[i] = 0
+		}
+
+		assigned += sizes[i]
+	}
+
+	// The remaining mutants go to the strata with the largest remainders
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; assigned < count && i < len(order); i++ {
+		if sizes[order[i]] < len(strata[keys[order[i]]]) {
+			sizes[order[i]]++
+			assigned++
+		}
+	}
+
+	r := rand.New(rand.NewSource(seed))
+
+	var selected []int
+	for i, k := range keys {
+		for _, j := range r.Perm(len(strata[k]))[:sizes[i]] {
+			selected = append(selected, strata[k][j])
+		}
+	}
+
+	// The sample keeps the order of the mutants
+	sort.Ints(selected)
+
+	sample := make([]*mutant, 0, len(selected))
+	for _, i := range selected {
+		sample = append(sample, mutants[i])
+	}
+
+	return sample
+}
+
+// ScoreInterval returns the 95% confidence interval of the mutation score, which is the Wilson score interval of the ratio of killed mutations.
+func (ms *mutationStats) ScoreInterval() (lower float64, upper float64) {
+	total := float64(ms.Total())
+	if total == 0 {
+		return 0.0, 0.0
+	}
+
+	const z = 1.96
+
+	score := ms.Score()
+	denominator := 1 + z*z/total
+	center := (score + z*z/(2*total)) / denominator
+	deviation := z / denominator * math.Sqrt(score*(1-score)/total+z*z/(4*total*total))
+
+	return math.Max(0, center-deviation), math.Min(1, center+deviation)
+}
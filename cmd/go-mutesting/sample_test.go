@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSampleSize(t *testing.T) {
+	count, percentage, err := parseSampleSize("500")
+	assert.Nil(t, err)
+	assert.Equal(t, 500, count)
+	assert.Equal(t, 0.0, percentage)
+
+	count, percentage, err = parseSampleSize("20%")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 20.0, percentage)
+
+	for _, size := range []string{"", "0", "-1", "0%", "101%", "a"} {
+		_, _, err := parseSampleSize(size)
+		assert.NotNil(t, err, size)
+	}
+}
+
+func TestSampleMutants(t *testing.T) {
+	var mutants []*mutant
+	for i := 0; i < 100; i++ {
+		pkgPath := "big"
+		if i%50 == 0 {
+			pkgPath = "small"
+		}
+
+		mutants = append(mutants, &mutant{
+			mutationFile: fmt.Sprintf("mutation.%d", i),
+			mutator:      fmt.Sprintf("mutator/%d", i%4),
+			pkgPath:      pkgPath,
+		})
+	}
+
+	opts := &options{}
+	opts.Filter.Sample = "10%"
+
+	sample := sampleMutants(opts, mutants, 1)
+	assert.Len(t, sample, 10)
+	// The same seed leads to the same sample
+	assert.Equal(t, sample, sampleMutants(opts, mutants, 1))
+	assert.NotEqual(t, sample, sampleMutants(opts, mutants, 2))
+
+	// Every stratum is part of the sample
+	opts.Filter.Sample = "3"
+	opts.Filter.Stratify = []string{"package"}
+
+	small := 0
+	for _, m := range sampleMutants(opts, mutants, 1) {
+		if m.pkgPath == "small" {
+			small++
+		}
+	}
+	assert.Equal(t, 1, small)
+
+	opts.Filter.Stratify = []string{"mutator"}
+
+	mutators := map[string]int{}
+	for _, m := range sampleMutants(opts, mutants, 1) {
+		mutators[m.mutator]++
+	}
+	assert.Len(t, mutators, 4)
+
+	// Samples larger than all mutants contain all mutants
+	opts.Filter.Sample = "1000"
+	assert.Equal(t, mutants, sampleMutants(opts, mutants, 1))
+}
+
+func TestScoreInterval(t *testing.T) {
+	lower, upper := (&mutationStats{}).ScoreInterval()
+	assert.Equal(t, 0.0, lower)
+	assert.Equal(t, 0.0, upper)
+
+	lower, upper = (&mutationStats{passed: 8, failed: 2}).ScoreInterval()
+	assert.InDelta(t, 0.490, lower, 0.001)
+	assert.InDelta(t, 0.943, upper, 0.001)
+
+	// More mutations narrow the interval
+	lower, upper = (&mutationStats{passed: 800, failed: 200}).ScoreInterval()
+	assert.InDelta(t, 0.774, lower, 0.001)
+	assert.InDelta(t, 0.824, upper, 0.001)
+}
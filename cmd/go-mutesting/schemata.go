@@ -0,0 +1,466 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/zimmski/go-mutesting"
+)
+
+// schemataEnv is the environment variable which selects the active mutation of a schemata test binary.
+const schemataEnv = "GO_MUTESTING_ACTIVE"
+
+// schemataHelperFile is the file which is added to the package of a schemata test binary to check which mutation is active.
+const schemataHelperFile = "go_mutesting_schemata.go"
+
+const schemataHelper = `package %s
+
+import (
+	"os"
+	"strconv"
+)
+
+var goMutestingActiveID, _ = strconv.Atoi(os.Getenv(%q))
+
+func goMutestingActive(id int) bool {
+	return id == goMutestingActiveID
+}
+`
+
+// schemaStatement is a statement of a statement list which can be replaced by a runtime switch between its mutations and itself.
+type schemaStatement struct {
+	// slot points to the statement in its list, so that mutations which replace the statement are visible too.
+	slot *ast.Stmt
+	pos  token.Pos
+	end  token.Pos
+}
+
+// schemaSite holds the mutated code of a statement of the original file.
+type schemaSite struct {
+	// start and end are the offsets of the original statement in the original file.
+	start int
+	end   int
+	code  string
+}
+
+// schemaRegion is a statement of the original file together with all mutations which change it.
+type schemaRegion struct {
+	start   int
+	end     int
+	mutants []*mutant
+}
+
+// schemaStatements returns all statements of the given node which can be replaced by a runtime switch.
+func schemaStatements(node ast.Node) []schemaStatement {
+	var statements []schemaStatement
+
+	add := func(list []ast.Stmt) {
+		for i := range list {
+			if switchable(list[i]) {
+				statements = append(statements, schemaStatement{
+					slot: &list[i],
+					pos:  list[i].Pos(),
+					end:  list[i].End(),
+				})
+			}
+		}
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			add(n.List)
+		case *ast.CaseClause:
+			add(n.Body)
+		case *ast.CommClause:
+			add(n.Body)
+		}
+
+		return true
+	})
+
+	return statements
+}
+
+// switchable returns true if the given statement stays valid if it is wrapped into the branches of an if statement.
+func switchable(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.CaseClause, *ast.CommClause:
+		return false
+	case *ast.DeclStmt:
+		// Declarations would not be visible after the branch
+		return false
+	case *ast.AssignStmt:
+		if s.Tok == token.DEFINE {
+			return false
+		}
+	case *ast.BranchStmt:
+		if s.Tok == token.FALLTHROUGH {
+			return false
+		}
+	}
+
+	// Labels cannot be declared twice in the same function
+	labeled := false
+	ast.Inspect(stmt, func(n ast.Node) bool {
+		if _, ok := n.(*ast.LabeledStmt); ok {
+			labeled = true
+		}
+
+		return !labeled
+	})
+
+	return !labeled
+}
+
+// newSchemaSite returns the mutated code of the innermost statement which contains the given changed node, or nil if the mutation cannot be expressed as runtime switch.
+func newSchemaSite(fset *token.FileSet, statements []schemaStatement, changed *mutesting.ChangedNode) (*schemaSite, error) {
+	var s *schemaStatement
+
+	for i := range statements {
+		c := &statements[i]
+
+		if c.pos <= changed.Pos && changed.End <= c.end && (s == nil || (s.pos <= c.pos && c.end <= s.end)) {
+			s = c
+		}
+	}
+
+	if s == nil {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, *s.slot); err != nil {
+		return nil, err
+	}
+
+	return &schemaSite{
+		start: fset.Position(s.pos).Offset,
+		end:   fset.Position(s.end).Offset,
+		code:  buf.String(),
+	}, nil
+}
+
+// renderSchema returns the given range of the original source in which every given region is replaced by a runtime switch between its mutations and the original code.
+// The regions must be sorted by their start and regions must precede the regions they contain.
+func renderSchema(src []byte, start int, end int, regions []schemaRegion) []byte {
+	var buf bytes.Buffer
+
+	pos := start
+
+	for i := 0; i < len(regions); {
+		r := regions[i]
+
+		// Regions are statements which are either disjoint or nested
+		j := i + 1
+		for j < len(regions) && regions[j].start < r.end {
+			j++
+		}
+
+		buf.Write(src[pos:r.start])
+
+		for k, m := range r.mutants {
+			if k > 0 {
+				buf.WriteString(" else ")
+			}
+
+			fmt.Fprintf(&buf, "if goMutestingActive(%d) {\n%s\n}", m.schemaID, m.schema.code)
+		}
+
+		buf.WriteString(" else {\n")
+		buf.Write(renderSchema(src, r.start, r.end, regions[i+1:j]))
+		buf.WriteString("\n}")
+
+		pos = r.end
+		i = j
+	}
+
+	buf.Write(src[pos:end])
+
+	return buf.Bytes()
+}
+
+// buildSchemata builds for every package one test binary which contains all mutations of the package that can be expressed as runtime switches.
+// Mutations which break the test binary are dropped from it and the test binary is built again. Mutations which cannot be switched at runtime or whose test binary cannot be built at all are compiled on their own.
+func buildSchemata(opts *options, tmpDir string, mutants []*mutant) {
+	var pkgPaths []string
+	pkgMutants := map[string][]*mutant{}
+
+	for _, m := range mutants {
+		if m.schema == nil || !m.executable() || m.cached != nil {
+			continue
+		}
+
+		if _, ok := pkgMutants[m.pkgPath]; !ok {
+			pkgPaths = append(pkgPaths, m.pkgPath)
+		}
+		pkgMutants[m.pkgPath] = append(pkgMutants[m.pkgPath], m)
+	}
+
+	for i, pkgPath := range pkgPaths {
+		ms := pkgMutants[pkgPath]
+
+		for {
+			binary, broken, err := buildSchema(filepath.Join(tmpDir, fmt.Sprintf("schemata.%d", i)), pkgPath, ms)
+			if err == nil {
+				for _, m := range ms {
+					m.schemaBinary = binary
+				}
+
+				verbose(opts, "Built %d mutations of package %q into %q which are switched at runtime", len(ms), pkgPath, binary)
+
+				break
+			} else if len(broken) == 0 {
+				fmt.Printf("Could not build the schemata of package %q, its mutations are compiled on their own: %v\n", pkgPath, err)
+
+				break
+			}
+
+			fmt.Printf("%d mutations of package %q break its schemata and are compiled on their own\n", len(broken), pkgPath)
+			debug(opts, "The schemata of package %q could not be built: %v", pkgPath, err)
+
+			ms = withoutMutants(ms, broken)
+			if len(ms) == 0 {
+				break
+			}
+		}
+	}
+}
+
+// withoutMutants returns the given mutants without the given removed mutants.
+func withoutMutants(mutants []*mutant, removed []*mutant) []*mutant {
+	skip := make(map[*mutant]struct{}, len(removed))
+	for _, m := range removed {
+		skip[m] = struct{}{}
+	}
+
+	var remaining []*mutant
+	for _, m := range mutants {
+		if _, ok := skip[m]; !ok {
+			remaining = append(remaining, m)
+		}
+	}
+
+	return remaining
+}
+
+// buildSchema writes the schemata of the given mutations of the given package into the given directory and returns the test binary which contains all of them.
+// If the test binary cannot be built, the mutations which are responsible for it are returned if they can be determined.
+func buildSchema(dir string, pkgPath string, mutants []*mutant) (binary string, broken []*mutant, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, err
+	}
+
+	var files []string
+	regions := map[string][]schemaRegion{}
+
+	for i, m := range mutants {
+		m.schemaID = i + 1
+
+		rs, ok := regions[m.file]
+		if !ok {
+			files = append(files, m.file)
+		}
+
+		found := false
+		for r := range rs {
+			if rs[r].start == m.schema.start && rs[r].end == m.schema.end {
+				rs[r].mutants = append(rs[r].mutants, m)
+				found = true
+
+				break
+			}
+		}
+		if !found {
+			rs = append(rs, schemaRegion{
+				start:   m.schema.start,
+				end:     m.schema.end,
+				mutants: []*mutant{m},
+			})
+		}
+
+		regions[m.file] = rs
+	}
+
+	replace := map[string]string{}
+	schemata := map[string][]byte{}
+	var pkgDir, pkgName string
+
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", nil, err
+		}
+
+		rs := regions[file]
+		sort.Slice(rs, func(i, j int) bool {
+			if rs[i].start != rs[j].start {
+				return rs[i].start < rs[j].start
+			}
+
+			return rs[i].end > rs[j].end
+		})
+
+		schema, err := format.Source(renderSchema(src, 0, len(src), rs))
+		if err != nil {
+			return "", unformattableMutants(src, rs), fmt.Errorf("could not render the schema of %q: %v", file, err)
+		}
+
+		absFile, err := filepath.Abs(file)
+		if err != nil {
+			return "", nil, err
+		}
+
+		schemaFile := filepath.Join(dir, filepath.Base(file))
+		if err := ioutil.WriteFile(schemaFile, schema, 0666); err != nil {
+			return "", nil, err
+		}
+
+		replace[absFile] = schemaFile
+		schemata[filepath.Base(schemaFile)] = schema
+
+		if pkgName == "" {
+			f, err := parser.ParseFile(token.NewFileSet(), file, src, parser.PackageClauseOnly)
+			if err != nil {
+				return "", nil, err
+			}
+
+			pkgDir = filepath.Dir(absFile)
+			pkgName = f.Name.Name
+		}
+	}
+
+	helperFile := filepath.Join(dir, schemataHelperFile)
+	if err := ioutil.WriteFile(helperFile, []byte(fmt.Sprintf(schemataHelper, pkgName, schemataEnv)), 0666); err != nil {
+		return "", nil, err
+	}
+	replace[filepath.Join(pkgDir, schemataHelperFile)] = helperFile
+
+	data, err := json.Marshal(struct {
+		Replace map[string]string
+	}{
+		Replace: replace,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	overlayFile := filepath.Join(dir, "overlay.json")
+	if err := ioutil.WriteFile(overlayFile, data, 0666); err != nil {
+		return "", nil, err
+	}
+
+	binary = filepath.Join(dir, "schemata.test")
+	_ = os.Remove(binary)
+
+	out, err := exec.Command("go", "test", "-c", "-o", binary, "-overlay", overlayFile, pkgPath).CombinedOutput()
+	if err != nil {
+		return "", brokenMutants(out, schemata, mutants), fmt.Errorf("%v\n%s", err, out)
+	}
+
+	// No test binary is built for packages without tests
+	if _, err := os.Stat(binary); err != nil {
+		return "", nil, fmt.Errorf("package has no tests")
+	}
+
+	return binary, nil, nil
+}
+
+// unformattableMutants returns the mutants of the given regions of the given source which cannot be rendered on their own.
+func unformattableMutants(src []byte, regions []schemaRegion) []*mutant {
+	var broken []*mutant
+
+	for _, r := range regions {
+		for _, m := range r.mutants {
+			if _, err := format.Source(renderSchema(src, 0, len(src), []schemaRegion{{start: r.start, end: r.end, mutants: []*mutant{m}}})); err != nil {
+				broken = append(broken, m)
+			}
+		}
+	}
+
+	return broken
+}
+
+// buildError matches the position of a compiler error.
+var buildError = regexp.MustCompile(`(?m)^(.+?\.go):(\d+):\d+: `)
+
+// brokenMutants returns the given mutants whose mutated code contains a position of the given compiler errors of the given schemata, which are mapped by the base names of their files.
+func brokenMutants(out []byte, schemata map[string][]byte, mutants []*mutant) []*mutant {
+	byID := map[int]*mutant{}
+	for _, m := range mutants {
+		byID[m.schemaID] = m
+	}
+
+	var broken []*mutant
+	seen := map[*mutant]struct{}{}
+
+	for _, e := range buildError.FindAllSubmatch(out, -1) {
+		// The go command shortens the paths of the overlay files, but it keeps their base names
+		schema, ok := schemata[filepath.Base(string(e[1]))]
+		if !ok {
+			continue
+		}
+		line, _ := strconv.Atoi(string(e[2]))
+
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "", schema, 0)
+		if err != nil {
+			continue
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			s, ok := n.(*ast.IfStmt)
+			if !ok {
+				return true
+			}
+
+			id, ok := schemaID(s.Cond)
+			if !ok || line < fset.Position(s.Body.Lbrace).Line || line > fset.Position(s.Body.Rbrace).Line {
+				return true
+			}
+
+			if m, ok := byID[id]; ok {
+				if _, ok := seen[m]; !ok {
+					seen[m] = struct{}{}
+					broken = append(broken, m)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return broken
+}
+
+// schemaID returns the identifier of the mutation which is checked by the given condition of a runtime switch.
+func schemaID(cond ast.Expr) (int, bool) {
+	c, ok := cond.(*ast.CallExpr)
+	if !ok || len(c.Args) != 1 {
+		return 0, false
+	}
+	if f, ok := c.Fun.(*ast.Ident); !ok || f.Name != "goMutestingActive" {
+		return 0, false
+	}
+	l, ok := c.Args[0].(*ast.BasicLit)
+	if !ok || l.Kind != token.INT {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(l.Value)
+
+	return id, err == nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zimmski/go-mutesting"
+)
+
+func TestSchemaStatements(t *testing.T) {
+	src := "package a\n\nfunc a(n int) int {\n\tm := 1\n\tif n > 0 {\n\t\tn++\n\t}\n\tswitch n {\n\tcase 1:\n\t\tfallthrough\n\tdefault:\n\t\tm--\n\t}\n\treturn n + m\n}\n"
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "a.go", src, 0)
+	assert.Nil(t, err)
+
+	var switchable []string
+	for _, s := range schemaStatements(f) {
+		switchable = append(switchable, src[fset.Position(s.pos).Offset:fset.Position(s.end).Offset])
+	}
+
+	// Neither the definition of m nor the fallthrough statement can be wrapped
+	assert.Equal(t, []string{
+		"if n > 0 {\n\t\tn++\n\t}",
+		"switch n {\n\tcase 1:\n\t\tfallthrough\n\tdefault:\n\t\tm--\n\t}",
+		"return n + m",
+		"n++",
+		"m--",
+	}, switchable)
+
+	// The innermost statement which contains the changed node is mutated
+	statements := schemaStatements(f)
+	increment := statements[3]
+	*increment.slot = &ast.IncDecStmt{X: ast.NewIdent("n"), Tok: token.DEC}
+
+	site, err := newSchemaSite(fset, statements, &mutesting.ChangedNode{Pos: increment.pos, End: increment.end})
+	assert.Nil(t, err)
+	assert.Equal(t, &schemaSite{start: fset.Position(increment.pos).Offset, end: fset.Position(increment.end).Offset, code: "n--"}, site)
+
+	// The function signature is not part of any statement
+	site, err = newSchemaSite(fset, statements, &mutesting.ChangedNode{Pos: f.Decls[0].Pos(), End: f.Decls[0].Pos() + 4})
+	assert.Nil(t, err)
+	assert.Nil(t, site)
+}
+
+func TestRenderSchema(t *testing.T) {
+	src := []byte("a()\nif b {\n\tc()\n}\nd()\n")
+
+	mutation := func(id int, code string) *mutant {
+		return &mutant{
+			schemaID: id,
+			schema:   &schemaSite{code: code},
+		}
+	}
+
+	assert.Equal(t, "a()\n"+
+		"if goMutestingActive(1) {\nif !b {\n\tc()\n}\n} else if goMutestingActive(2) {\n_ = b\n} else {\n"+
+		"if b {\n\tif goMutestingActive(3) {\n_ = c\n} else {\nc()\n}\n}"+
+		"\n}\nd()\n", string(renderSchema(src, 0, len(src), []schemaRegion{
+		{start: 4, end: 17, mutants: []*mutant{mutation(1, "if !b {\n\tc()\n}"), mutation(2, "_ = b")}},
+		{start: 12, end: 15, mutants: []*mutant{mutation(3, "_ = c")}},
+	})))
+}
+
+func TestBuildSchemata(t *testing.T) {
+	source := "package schema\n\nfunc Abs(a int) int {\n\tif a < 0 {\n\t\ta = -a\n\t}\n\n\treturn a\n}\n"
+	dir, removeModule := writeModule(
+		t,
+		"schema",
+		source,
+		"package schema\n\nimport \"testing\"\n\nfunc TestAbs(t *testing.T) {\n\tif Abs(-1) != 1 {\n\t\tt.Fail()\n\t}\n}\n",
+	)
+	defer removeModule()
+
+	saveCwd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer func() {
+		assert.Nil(t, os.Chdir(saveCwd))
+	}()
+
+	start := strings.Index(source, "a = -a")
+	mutation := func(code string) *mutant {
+		return &mutant{
+			file:    filepath.Join(dir, "schema.go"),
+			pkgPath: "schema",
+			schema:  &schemaSite{start: start, end: start + len("a = -a"), code: code},
+		}
+	}
+
+	valid := mutation("a = a")
+	unused := mutation("b := a")
+	unformattable := mutation("a = (")
+
+	// Mutations which break the schemata are dropped from it
+	buildSchemata(&options{}, dir, []*mutant{valid, unused, unformattable})
+	assert.NotEmpty(t, valid.schemaBinary)
+	assert.Empty(t, unused.schemaBinary)
+	assert.Empty(t, unformattable.schemaBinary)
+}
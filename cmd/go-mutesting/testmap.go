@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/cover"
+)
+
+// testMap maps the lines of the files of a package to the tests which execute them.
+type testMap struct {
+	// Checksum identifies the source and test files from which the map was built.
+	Checksum string
+	// Lines maps every line of a file which is instrumented by the coverage to the tests which execute the line.
+	Lines map[string]map[int][]string
+}
+
+// Tests returns the tests which execute at least one of the given lines of the given file.
+// If none of the lines is instrumented by the coverage, e.g. declarations outside of functions, it is unknown which tests execute the lines and false is returned.
+func (tm *testMap) Tests(file string, startLine int, endLine int) ([]string, bool) {
+	instrumented := false
+	tests := map[string]struct{}{}
+
+	for line := startLine; line <= endLine; line++ {
+		lineTests, ok := tm.Lines[file][line]
+		if !ok {
+			continue
+		}
+
+		instrumented = true
+
+		for _, test := range lineTests {
+			tests[test] = struct{}{}
+		}
+	}
+
+	if !instrumented {
+		return nil, false
+	}
+
+	l := make([]string, 0, len(tests))
+	for test := range tests {
+		l = append(l, test)
+	}
+	sort.Strings(l)
+
+	return l, true
+}
+
+var testNamePattern = regexp.MustCompile(`^(Test|Example|Fuzz)\w*$`)
+
+// loadTestMap returns the test map of the given package which is cached as long as no source or test file of the package changes.
+func loadTestMap(opts *options, pkgPath string, dir string) (*testMap, error) {
+	checksum, err := packageChecksum(dir, opts.Test.Recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDir, err := cacheDirectory("tests")
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The same package might be checked out more than once
+	cacheFile := filepath.Join(cacheDir, fmt.Sprintf("%x.json", md5.Sum([]byte(absDir+"\x00"+pkgPath))))
+
+	if data, err := ioutil.ReadFile(cacheFile); err == nil {
+		var tm testMap
+		if err := json.Unmarshal(data, &tm); err == nil && tm.Checksum == checksum {
+			debug(opts, "Use cached test map %q of package %q", cacheFile, pkgPath)
+
+			return &tm, nil
+		}
+	}
+
+	tm, err := buildTestMap(opts, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	tm.Checksum = checksum
+
+	data, err := json.Marshal(tm)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(cacheFile, data, 0644); err != nil {
+		return nil, err
+	}
+
+	return tm, nil
+}
+
+// buildTestMap executes every top-level test of the given package on its own with coverage and maps the executed lines to the tests.
+func buildTestMap(opts *options, pkgPath string) (*testMap, error) {
+	pkgName := pkgPath
+	if opts.Test.Recursive {
+		pkgName += "/..."
+	}
+
+	list, err := exec.Command("go", "test", "-list", ".", pkgName).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("could not list tests of package %q: %v\n%s", pkgName, err, list)
+	}
+
+	var tests []string
+	s := bufio.NewScanner(bytes.NewReader(list))
+	for s.Scan() {
+		if testNamePattern.MatchString(s.Text()) {
+			tests = append(tests, s.Text())
+		}
+	}
+
+	profileFile, err := ioutil.TempFile("", "go-mutesting-coverage-")
+	if err != nil {
+		return nil, err
+	}
+	if err := profileFile.Close(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(profileFile.Name())
+	}()
+
+	tm := &testMap{
+		Lines: map[string]map[int][]string{},
+	}
+
+	for _, test := range tests {
+		verbose(opts, "Record coverage of test %q of package %q", test, pkgName)
+
+		out, err := exec.Command("go", "test", "-count=1", "-run", "^"+regexp.QuoteMeta(test)+"$", "-coverpkg", pkgPath, "-coverprofile", profileFile.Name(), pkgName).CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("could not record coverage of test %q of package %q: %v\n%s", test, pkgName, err, out)
+		}
+
+		profiles, err := cover.ParseProfiles(profileFile.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range profiles {
+			lines, ok := tm.Lines[p.FileName]
+			if !ok {
+				lines = map[int][]string{}
+				tm.Lines[p.FileName] = lines
+			}
+
+			for _, b := range p.Blocks {
+				for line := b.StartLine; line <= b.EndLine; line++ {
+					l := lines[line]
+					if l == nil {
+						l = []string{}
+					}
+
+					if b.Count > 0 && (len(l) == 0 || l[len(l)-1] != test) {
+						l = append(l, test)
+					}
+
+					lines[line] = l
+				}
+			}
+		}
+	}
+
+	return tm, nil
+}
+
+// cacheDirectory returns the given directory of the cache of go-mutesting and creates it if needed.
+func cacheDirectory(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "go-mutesting", name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// packageChecksum returns a checksum of all Go files of the given package directory.
+func packageChecksum(dir string, recursive bool) (string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return filesChecksum(files)
+}
+
+// filesChecksum returns a checksum of the paths and contents of the given files.
+func filesChecksum(files []string) (string, error) {
+	sort.Strings(files)
+
+	h := md5.New()
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+
+		_, _ = io.WriteString(h, file)
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// selectTests sets the tests of all given mutants of the given package to the tests which execute the changed lines of the mutants.
+// Mutants whose changed lines are not executed by any test are marked as not covered.
+func selectTests(opts *options, pkgPath string, dir string, mutants []*mutant) error {
+	tm, err := loadTestMap(opts, pkgPath, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range mutants {
+		if m.pkgPath != pkgPath {
+			continue
+		}
+
+		tests, ok := tm.Tests(coverageFile(m), m.start.Line, m.end.Line)
+		if !ok {
+			continue
+		}
+
+		if len(tests) == 0 {
+			m.notCovered = true
+		} else {
+			m.tests = tests
+		}
+	}
+
+	return nil
+}
+
+// testsPattern returns a pattern for the -run argument of the go test command which matches exactly the given tests, or an empty pattern if no tests are given.
+func testsPattern(tests []string) string {
+	if len(tests) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(tests))
+	for i, test := range tests {
+		quoted[i] = regexp.QuoteMeta(test)
+	}
+
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
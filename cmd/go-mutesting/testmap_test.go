@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestMapTests(t *testing.T) {
+	tm := &testMap{
+		Lines: map[string]map[int][]string{
+			"example/example.go": {
+				3: {"TestA", "TestB"},
+				4: {"TestB", "TestC"},
+				5: {},
+			},
+		},
+	}
+
+	tests, ok := tm.Tests("example/example.go", 3, 4)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"TestA", "TestB", "TestC"}, tests)
+
+	tests, ok = tm.Tests("example/example.go", 5, 6)
+	assert.True(t, ok)
+	assert.Empty(t, tests)
+
+	_, ok = tm.Tests("example/example.go", 1, 2)
+	assert.False(t, ok)
+	_, ok = tm.Tests("example/other.go", 3, 4)
+	assert.False(t, ok)
+}
+
+func TestTestsPattern(t *testing.T) {
+	assert.Equal(t, "", testsPattern(nil))
+	assert.Equal(t, "^(TestA|TestB)$", testsPattern([]string{"TestA", "TestB"}))
+}
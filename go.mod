@@ -1,6 +1,6 @@
 module github.com/zimmski/go-mutesting
 
-go 1.10
+go 1.21
 
 require (
 	github.com/davecgh/go-spew v1.1.0
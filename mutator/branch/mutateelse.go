@@ -34,6 +34,7 @@ func MutatorElse(pkg *types.Package, info *types.Info, node ast.Node) []mutator.
 			Reset: func() {
 				n.Else = old
 			},
+			Node: old,
 		},
 	}
 }
@@ -31,6 +31,7 @@ func MutatorIf(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mu
 			Reset: func() {
 				n.Body.List = old
 			},
+			Node: n.Body,
 		},
 	}
 }
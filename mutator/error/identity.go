@@ -0,0 +1,242 @@
+package error
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/zimmski/go-mutesting/mutator"
+)
+
+func init() {
+	mutator.Register("error/identity", MutatorIdentity)
+}
+
+// MutatorIdentity implements a mutator to change the identity and wrapping of errors.
+// It replaces errors.Is calls with simple comparisons, lets errors.As calls always fail, drops the %w wrapping of fmt.Errorf calls and replaces returned sentinel errors with other sentinel errors of the same package.
+func MutatorIdentity(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
+	var mutations []mutator.Mutation
+
+	for _, slot := range expressionSlots(node) {
+		if n, ok := (*slot).(*ast.CallExpr); ok && isFunction(info, n, "errors", "Is") && len(n.Args) == 2 {
+			s := slot
+			old := *slot
+
+			mutations = append(mutations, mutator.Mutation{
+				Change: func() {
+					*s = &ast.BinaryExpr{
+						X:  n.Args[0],
+						Op: token.EQL,
+						Y:  n.Args[1],
+					}
+				},
+				Reset: func() {
+					*s = old
+				},
+				Node: old,
+			})
+		}
+	}
+
+	switch n := node.(type) {
+	case *ast.CallExpr:
+		if isFunction(info, n, "errors", "As") && len(n.Args) == 2 {
+			old := n.Args[0]
+
+			mutations = append(mutations, mutator.Mutation{
+				Change: func() {
+					n.Args[0] = ast.NewIdent("nil")
+				},
+				Reset: func() {
+					n.Args[0] = old
+				},
+				Node: old,
+			})
+		} else if isFunction(info, n, "fmt", "Errorf") && len(n.Args) > 0 {
+			if l, ok := n.Args[0].(*ast.BasicLit); ok && l.Kind == token.STRING {
+				old := l.Value
+				r := replaceWrapVerb(old)
+
+				if r != old {
+					mutations = append(mutations, mutator.Mutation{
+						Change: func() {
+							l.Value = r
+						},
+						Reset: func() {
+							l.Value = old
+						},
+						Node: l,
+					})
+				}
+			}
+		}
+	case *ast.ReturnStmt:
+		for _, r := range n.Results {
+			var id *ast.Ident
+
+			switch e := r.(type) {
+			case *ast.Ident:
+				id = e
+			case *ast.SelectorExpr:
+				id = e.Sel
+			default:
+				continue
+			}
+
+			mutations = append(mutations, mutateSentinel(pkg, info, id)...)
+		}
+	}
+
+	return mutations
+}
+
+// expressionSlots returns the expression slots of a node which can hold a call expression.
+func expressionSlots(node ast.Node) []*ast.Expr {
+	var slots []*ast.Expr
+
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		for i := range n.Rhs {
+			slots = append(slots, &n.Rhs[i])
+		}
+	case *ast.BinaryExpr:
+		slots = append(slots, &n.X, &n.Y)
+	case *ast.CallExpr:
+		for i := range n.Args {
+			slots = append(slots, &n.Args[i])
+		}
+	case *ast.CaseClause:
+		for i := range n.List {
+			slots = append(slots, &n.List[i])
+		}
+	case *ast.CompositeLit:
+		for i := range n.Elts {
+			slots = append(slots, &n.Elts[i])
+		}
+	case *ast.ForStmt:
+		slots = append(slots, &n.Cond)
+	case *ast.IfStmt:
+		slots = append(slots, &n.Cond)
+	case *ast.KeyValueExpr:
+		slots = append(slots, &n.Value)
+	case *ast.ParenExpr:
+		slots = append(slots, &n.X)
+	case *ast.ReturnStmt:
+		for i := range n.Results {
+			slots = append(slots, &n.Results[i])
+		}
+	case *ast.SwitchStmt:
+		slots = append(slots, &n.Tag)
+	case *ast.UnaryExpr:
+		slots = append(slots, &n.X)
+	case *ast.ValueSpec:
+		for i := range n.Values {
+			slots = append(slots, &n.Values[i])
+		}
+	}
+
+	return slots
+}
+
+// isFunction returns true if the given call calls the function with the given name of the package with the given import path.
+func isFunction(info *types.Info, n *ast.CallExpr, path string, name string) bool {
+	var id *ast.Ident
+
+	switch f := n.Fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return false
+	}
+
+	f, ok := info.Uses[id].(*types.Func)
+
+	return ok && f.Pkg() != nil && f.Pkg().Path() == path && f.Name() == name
+}
+
+// replaceWrapVerb replaces every %w verb of the given format string with %v.
+func replaceWrapVerb(format string) string {
+	var r strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		r.WriteByte(format[i])
+
+		if format[i] != '%' || i+1 == len(format) {
+			continue
+		}
+
+		i++
+		if format[i] == 'w' {
+			r.WriteByte('v')
+		} else {
+			r.WriteByte(format[i])
+		}
+	}
+
+	return r.String()
+}
+
+// mutateSentinel returns mutations which replace the sentinel error of the given identifier with every other sentinel error of the same package.
+func mutateSentinel(pkg *types.Package, info *types.Info, id *ast.Ident) []mutator.Mutation {
+	errorType := types.Universe.Lookup("error").Type()
+
+	v, ok := info.Uses[id].(*types.Var)
+	if !ok || v.Pkg() == nil || !types.Identical(v.Type(), errorType) {
+		return nil
+	}
+
+	// Only package level variables can be sentinel errors
+	scope := v.Pkg().Scope()
+	if scope.Lookup(v.Name()) != v {
+		return nil
+	}
+
+	// Unqualified sentinel errors are replaced by unqualified sentinel errors which could be shadowed at this position
+	inner := pkg.Scope().Innermost(id.Pos())
+	if inner != nil {
+		if _, o := inner.LookupParent(v.Name(), id.Pos()); o != v {
+			inner = nil
+		}
+	}
+
+	old := id.Name
+
+	var mutations []mutator.Mutation
+
+	for _, name := range scope.Names() {
+		if name == v.Name() || name == "_" {
+			continue
+		}
+
+		r, ok := scope.Lookup(name).(*types.Var)
+		if !ok || !types.Identical(r.Type(), errorType) {
+			continue
+		}
+
+		// Variables of other packages are only accessible if they are exported
+		if r.Pkg() != pkg && !r.Exported() {
+			continue
+		}
+
+		if inner != nil {
+			if _, o := inner.LookupParent(name, id.Pos()); o != r {
+				continue
+			}
+		}
+
+		mutations = append(mutations, mutator.Mutation{
+			Change: func() {
+				id.Name = r.Name()
+			},
+			Reset: func() {
+				id.Name = old
+			},
+			Node: id,
+		})
+	}
+
+	return mutations
+}
@@ -0,0 +1,33 @@
+package error
+
+import (
+	"testing"
+
+	"github.com/zimmski/go-mutesting/test"
+)
+
+func TestMutatorIdentity(t *testing.T) {
+	test.Mutator(
+		t,
+		MutatorIdentity,
+		"../../testdata/error/identity.go",
+		9,
+	)
+
+	test.MutatorNodes(
+		t,
+		MutatorIdentity,
+		"../../testdata/error/identity.go",
+		[]string{
+			"ErrNotFound",
+			"EOF",
+			"EOF",
+			"EOF",
+			"EOF",
+			"EOF",
+			`"cannot find %q: %w"`,
+			"errors.Is(err, ErrClosed)",
+			"err",
+		},
+	)
+}
@@ -0,0 +1,68 @@
+package expression
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/zimmski/go-mutesting/mutator"
+)
+
+func init() {
+	mutator.Register("expression/argswap", MutatorArgumentSwap)
+}
+
+// MutatorArgumentSwap implements a mutator to swap two arguments of a call which have identical types.
+func MutatorArgumentSwap(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
+	n, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	if len(n.Args) < 2 {
+		return nil
+	}
+
+	// Swapping the arguments of commutative built-in functions leads to equivalent mutations
+	if id, ok := n.Fun.(*ast.Ident); ok {
+		if b, ok := info.Uses[id].(*types.Builtin); ok && (b.Name() == "min" || b.Name() == "max") {
+			return nil
+		}
+	}
+
+	var mutations []mutator.Mutation
+
+	for i := 0; i < len(n.Args); i++ {
+		ti, ok := info.Types[n.Args[i]]
+		if !ok || ti.IsType() {
+			continue
+		}
+
+		for j := i + 1; j < len(n.Args); j++ {
+			tj, ok := info.Types[n.Args[j]]
+			if !ok || tj.IsType() || !types.Identical(ti.Type, tj.Type) {
+				continue
+			}
+
+			// Swapping identical expressions does not change anything
+			if types.ExprString(n.Args[i]) == types.ExprString(n.Args[j]) {
+				continue
+			}
+
+			li := i
+			lj := j
+			x := n.Args[li]
+			y := n.Args[lj]
+
+			mutations = append(mutations, mutator.Mutation{
+				Change: func() {
+					n.Args[li], n.Args[lj] = y, x
+				},
+				Reset: func() {
+					n.Args[li], n.Args[lj] = x, y
+				},
+				Node: n,
+			})
+		}
+	}
+
+	return mutations
+}
@@ -0,0 +1,27 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/zimmski/go-mutesting/test"
+)
+
+func TestMutatorArgumentSwap(t *testing.T) {
+	test.Mutator(
+		t,
+		MutatorArgumentSwap,
+		"../../testdata/expression/argswap.go",
+		3,
+	)
+
+	test.MutatorNodes(
+		t,
+		MutatorArgumentSwap,
+		"../../testdata/expression/argswap.go",
+		[]string{
+			"copy(dst, src)",
+			"NewRange(1, 10)",
+			"NewRange(lo, hi)",
+		},
+	)
+}
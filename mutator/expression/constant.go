@@ -0,0 +1,83 @@
+package expression
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"github.com/zimmski/go-mutesting/mutator"
+)
+
+func init() {
+	mutator.Register("expression/constant", MutatorConstant)
+}
+
+// MutatorConstant implements a mutator to replace named constants with other constants of the same named type.
+func MutatorConstant(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
+	n, ok := node.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	c, ok := info.Uses[n].(*types.Const)
+	if !ok || c.Pkg() == nil {
+		return nil
+	}
+	t, ok := c.Type().(*types.Named)
+	if !ok {
+		return nil
+	}
+
+	// Unqualified constants are replaced by unqualified constants which could be shadowed at this position
+	inner := pkg.Scope().Innermost(n.Pos())
+	if inner != nil {
+		if _, o := inner.LookupParent(c.Name(), n.Pos()); o != c {
+			inner = nil
+		}
+	}
+
+	old := n.Name
+
+	var mutations []mutator.Mutation
+
+	scope := c.Pkg().Scope()
+	for _, name := range scope.Names() {
+		if name == c.Name() || name == "_" {
+			continue
+		}
+
+		r, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(r.Type(), t) {
+			continue
+		}
+
+		// Constants of other packages are only accessible if they are exported
+		if r.Pkg() != pkg && !r.Exported() {
+			continue
+		}
+
+		// Constants with the same value would lead to an equivalent mutation
+		if constant.Compare(r.Val(), token.EQL, c.Val()) {
+			continue
+		}
+
+		if inner != nil {
+			if _, o := inner.LookupParent(name, n.Pos()); o != r {
+				continue
+			}
+		}
+
+		mutations = append(mutations, mutator.Mutation{
+			Change: func() {
+				n.Name = r.Name()
+			},
+			Reset: func() {
+				n.Name = old
+			},
+			Node: n,
+		})
+	}
+
+	return mutations
+}
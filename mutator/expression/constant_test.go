@@ -0,0 +1,41 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/zimmski/go-mutesting/test"
+)
+
+func TestMutatorConstant(t *testing.T) {
+	test.Mutator(
+		t,
+		MutatorConstant,
+		"../../testdata/expression/constant.go",
+		17,
+	)
+
+	test.MutatorNodes(
+		t,
+		MutatorConstant,
+		"../../testdata/expression/constant.go",
+		[]string{
+			"StatusActive",
+			"StatusActive",
+			"StatusPending",
+			"StatusPending",
+			"StatusPending",
+			"StatusActive",
+			"StatusActive",
+			"StatusPending",
+			"StatusPending",
+			"StatusPending",
+			"Second",
+			"Second",
+			"Second",
+			"Second",
+			"Second",
+			"StatusPending",
+			"StatusPending",
+		},
+	)
+}
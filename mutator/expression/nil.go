@@ -0,0 +1,90 @@
+package expression
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/zimmski/go-mutesting/mutator"
+)
+
+func init() {
+	mutator.Register("expression/nil", MutatorNil)
+}
+
+// nillable returns true if nil is assignable to the given type.
+func nillable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Chan, *types.Interface, *types.Map, *types.Pointer, *types.Signature, *types.Slice:
+		return true
+	}
+
+	return false
+}
+
+// MutatorNil implements a mutator to replace arguments and assigned values with nil.
+func MutatorNil(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
+	var slots []*ast.Expr
+
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		if n.Tok != token.ASSIGN {
+			return nil
+		}
+
+		for i := range n.Rhs {
+			// nil cannot be assigned to the blank identifier
+			if len(n.Lhs) == len(n.Rhs) {
+				if id, ok := n.Lhs[i].(*ast.Ident); ok && id.Name == "_" {
+					continue
+				}
+			}
+
+			slots = append(slots, &n.Rhs[i])
+		}
+	case *ast.CallExpr:
+		// Conversions and built-in functions are most of the time not compilable with nil arguments
+		if tv, ok := info.Types[n.Fun]; ok && (tv.IsType() || tv.IsBuiltin()) {
+			return nil
+		}
+
+		for i := range n.Args {
+			slots = append(slots, &n.Args[i])
+		}
+	case *ast.ValueSpec:
+		// Without an explicit type the type of nil cannot be inferred
+		if n.Type == nil {
+			return nil
+		}
+
+		for i := range n.Values {
+			slots = append(slots, &n.Values[i])
+		}
+	default:
+		return nil
+	}
+
+	var mutations []mutator.Mutation
+
+	for _, slot := range slots {
+		tv, ok := info.Types[*slot]
+		if !ok || tv.Type == nil || tv.IsNil() || !nillable(tv.Type) {
+			continue
+		}
+
+		s := slot
+		old := *slot
+
+		mutations = append(mutations, mutator.Mutation{
+			Change: func() {
+				*s = ast.NewIdent("nil")
+			},
+			Reset: func() {
+				*s = old
+			},
+			Node: old,
+		})
+	}
+
+	return mutations
+}
@@ -0,0 +1,32 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/zimmski/go-mutesting/test"
+)
+
+func TestMutatorNil(t *testing.T) {
+	test.Mutator(
+		t,
+		MutatorNil,
+		"../../testdata/expression/nil.go",
+		8,
+	)
+
+	test.MutatorNodes(
+		t,
+		MutatorNil,
+		"../../testdata/expression/nil.go",
+		[]string{
+			"data",
+			"func(s string) {}",
+			"&buf",
+			"p",
+			"data",
+			"&buf",
+			"append(data, '!')",
+			"map[string]int{}",
+		},
+	)
+}
@@ -0,0 +1,112 @@
+package expression
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/zimmski/go-mutesting/mutator"
+)
+
+func init() {
+	mutator.Register("expression/variable", MutatorVariable)
+}
+
+// rvalues returns the expression slots of a node which are only read.
+func rvalues(node ast.Node) []*ast.Expr {
+	var slots []*ast.Expr
+
+	switch n := node.(type) {
+	case *ast.AssignStmt:
+		for i := range n.Rhs {
+			slots = append(slots, &n.Rhs[i])
+		}
+	case *ast.BinaryExpr:
+		slots = append(slots, &n.X, &n.Y)
+	case *ast.CallExpr:
+		for i := range n.Args {
+			slots = append(slots, &n.Args[i])
+		}
+	case *ast.IndexExpr:
+		slots = append(slots, &n.Index)
+	case *ast.ReturnStmt:
+		for i := range n.Results {
+			slots = append(slots, &n.Results[i])
+		}
+	case *ast.SendStmt:
+		slots = append(slots, &n.Value)
+	case *ast.ValueSpec:
+		for i := range n.Values {
+			slots = append(slots, &n.Values[i])
+		}
+	}
+
+	return slots
+}
+
+// MutatorVariable implements a mutator to replace variables with other variables of the identical type which are visible at the same position.
+func MutatorVariable(pkg *types.Package, info *types.Info, node ast.Node) []mutator.Mutation {
+	var mutations []mutator.Mutation
+
+	for _, slot := range rvalues(node) {
+		n, ok := (*slot).(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		v, ok := info.Uses[n].(*types.Var)
+		if !ok || v.IsField() {
+			continue
+		}
+
+		s := slot
+		old := *slot
+
+		for _, r := range variableReplacements(pkg, n, v) {
+			r := r
+
+			mutations = append(mutations, mutator.Mutation{
+				Change: func() {
+					*s = ast.NewIdent(r.Name())
+				},
+				Reset: func() {
+					*s = old
+				},
+				Node: old,
+			})
+		}
+	}
+
+	return mutations
+}
+
+// variableReplacements returns all variables with the identical type of the given variable which are visible at the position of the given identifier, beginning with the innermost scope.
+func variableReplacements(pkg *types.Package, n *ast.Ident, v *types.Var) []*types.Var {
+	inner := pkg.Scope().Innermost(n.Pos())
+	if inner == nil {
+		return nil
+	}
+
+	var replacements []*types.Var
+
+	for s := inner; s != nil && s != types.Universe; s = s.Parent() {
+		for _, name := range s.Names() {
+			if name == "_" || name == v.Name() {
+				continue
+			}
+
+			r, ok := s.Lookup(name).(*types.Var)
+			if !ok || !types.Identical(r.Type(), v.Type()) {
+				continue
+			}
+
+			// Ignore variables which are declared later or which are shadowed at this position
+			if _, o := inner.LookupParent(name, n.Pos()); o != r {
+				continue
+			}
+
+			replacements = append(replacements, r)
+		}
+	}
+
+	return replacements
+}
@@ -0,0 +1,36 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/zimmski/go-mutesting/test"
+)
+
+func TestMutatorVariable(t *testing.T) {
+	test.Mutator(
+		t,
+		MutatorVariable,
+		"../../testdata/expression/variable.go",
+		12,
+	)
+
+	test.MutatorNodes(
+		t,
+		MutatorVariable,
+		"../../testdata/expression/variable.go",
+		[]string{
+			"i",
+			"i",
+			"j",
+			"j",
+			"k",
+			"k",
+			"k",
+			"offset",
+			"a",
+			"a",
+			"b",
+			"b",
+		},
+	)
+}
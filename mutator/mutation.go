@@ -1,9 +1,15 @@
 package mutator
 
+import (
+	"go/ast"
+)
+
 // Mutation defines the behavior of one mutation
 type Mutation struct {
 	// Change is called before executing the exec command.
 	Change func()
 	// Reset is called after executing the exec command.
 	Reset func()
+	// Node is the node which is changed by the mutation. It is optional since by default the node which was given to the mutator is changed.
+	Node ast.Node
 }
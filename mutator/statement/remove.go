@@ -51,6 +51,7 @@ func MutatorRemoveStatement(pkg *types.Package, info *types.Info, node ast.Node)
 				Reset: func() {
 					l[li] = old
 				},
+				Node: old,
 			})
 		}
 	}
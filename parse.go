@@ -8,7 +8,11 @@ import (
 	"go/token"
 	"go/types"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -40,44 +44,221 @@ func ParseSource(data interface{}) (*ast.File, *token.FileSet, error) {
 // ParseAndTypeCheckFile parses and type-checks the given file, and returns everything interesting about the file.
 // If a fatal error is encountered the error return argument is not nil.
 func ParseAndTypeCheckFile(file string, flags ...string) (*ast.File, *token.FileSet, *types.Package, *types.Info, error) {
-	fileAbs, err := filepath.Abs(file)
+	files, err := ParseAndTypeCheckFiles([]string{file}, flags...)
 	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("could not absolute the file path of %q: %v", file, err)
+		return nil, nil, nil, nil, err
 	}
-	dir := filepath.Dir(fileAbs)
 
-	buildPkg, err := build.ImportDir(dir, build.FindOnly)
-	if err != nil {
-		return nil, nil, nil, nil, fmt.Errorf("could not create build package of %q: %v", file, err)
+	f := files[0]
+
+	return f.Src, f.Fset, f.Pkg, f.Info, nil
+}
+
+// TypeCheckedFile holds a parsed and type-checked file together with its package.
+type TypeCheckedFile struct {
+	// File is the path of the file as it was given.
+	File string
+
+	Src  *ast.File
+	Fset *token.FileSet
+	Pkg  *types.Package
+	Info *types.Info
+
+	// Files holds all parsed files of the package including Src.
+	Files []*ast.File
+	// Sizes holds the sizes of the types of the target platform of the package.
+	Sizes types.Sizes
+	// GoVersion holds the Go version of the module of the package, e.g. "go1.21", or is empty if it is unknown.
+	GoVersion string
+}
+
+// ParseAndTypeCheckFiles parses and type-checks the given files, and returns everything interesting about the files in the given order.
+// The packages of all files are loaded at once, so that every package and its dependencies are only type-checked once. All files share the same file set.
+// If a fatal error is encountered the error return argument is not nil.
+func ParseAndTypeCheckFiles(files []string, flags ...string) ([]*TypeCheckedFile, error) {
+	var pkgPaths []string
+	pkgPathOfDir := map[string]string{}
+	goVersionOfDir := map[string]string{}
+	filesAbs := make([]string, len(files))
+
+	for i, file := range files {
+		fileAbs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not absolute the file path of %q: %v", file, err)
+		}
+		filesAbs[i] = fileAbs
+
+		dir := filepath.Dir(fileAbs)
+		if _, ok := pkgPathOfDir[dir]; ok {
+			continue
+		}
+
+		buildPkg, err := build.ImportDir(dir, build.FindOnly)
+		if err != nil {
+			return nil, fmt.Errorf("could not create build package of %q: %v", file, err)
+		}
+
+		pkgPath := buildPkg.ImportPath
+		if buildPkg.ImportPath == "." {
+			pkgPath = dir
+		}
+
+		pkgPathOfDir[dir] = pkgPath
+		pkgPaths = append(pkgPaths, pkgPath)
+
+		goVersion, err := moduleGoVersion(dir)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine the Go version of %q: %v", file, err)
+		}
+		goVersionOfDir[dir] = goVersion
 	}
 
-	pkgPath := buildPkg.ImportPath
-	if buildPkg.ImportPath == "." {
-		pkgPath = dir
+	if len(pkgPaths) == 0 {
+		return nil, nil
 	}
 
-	prog, err := packages.Load(&packages.Config{
+	pkgs, err := packages.Load(&packages.Config{
 		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
 			return parser.ParseFile(fset, filename, src, parser.ParseComments|parser.AllErrors)
 		},
 		BuildFlags: flags,
-		Mode:       packages.NeedTypes | packages.NeedSyntax | packages.NeedDeps | packages.NeedName | packages.NeedImports | packages.NeedTypesInfo | packages.NeedFiles,
-	}, pkgPath)
+		Mode:       packages.NeedTypes | packages.NeedSyntax | packages.NeedDeps | packages.NeedName | packages.NeedImports | packages.NeedTypesInfo | packages.NeedFiles | packages.NeedTypesSizes,
+	}, pkgPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages %s: %v", strings.Join(pkgPaths, ", "), err)
+	}
+
+	syntax := map[string]*TypeCheckedFile{}
+	for _, pkg := range pkgs {
+		for _, f := range pkg.Syntax {
+			syntax[pkg.Fset.Position(f.Pos()).Filename] = &TypeCheckedFile{
+				Src:  f,
+				Fset: pkg.Fset,
+				Pkg:  pkg.Types,
+				Info: pkg.TypesInfo,
+
+				Files: pkg.Syntax,
+				Sizes: pkg.TypesSizes,
+			}
+		}
+	}
+
+	typeChecked := make([]*TypeCheckedFile, len(files))
+	for i, file := range files {
+		f, ok := syntax[filesAbs[i]]
+		if !ok {
+			return nil, fmt.Errorf("could not load package of file %q", file)
+		}
+
+		typeChecked[i] = &TypeCheckedFile{
+			File: file,
+			Src:  f.Src,
+			Fset: f.Fset,
+			Pkg:  f.Pkg,
+			Info: f.Info,
+
+			Files:     f.Files,
+			Sizes:     f.Sizes,
+			GoVersion: goVersionOfDir[filepath.Dir(filesAbs[i])],
+		}
+	}
+
+	return typeChecked, nil
+}
+
+// TypeCheckMutation type-checks the given mutated source of the given file in memory together with the other files of its package, and returns the first type error if the mutation does not compile.
+// The already loaded imports of the package are reused. If the package cannot be type-checked in memory, e.g. because it uses cgo, nil is returned since only the go command can tell.
+func TypeCheckMutation(f *TypeCheckedFile, mutation []byte) error {
+	imports := map[string]*types.Package{
+		"unsafe": types.Unsafe,
+	}
+	for _, pkg := range f.Pkg.Imports() {
+		imports[pkg.Path()] = pkg
+	}
+
+	// The mutation is parsed into its own file set, since the file set of the package is shared and must not grow with every mutation
+	var others []*token.File
+	for _, file := range f.Files {
+		if file != f.Src {
+			others = append(others, f.Fset.File(file.Pos()))
+		}
+	}
+
+	// The other files of the package keep their positions, which need to be added in ascending order
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].Base() < others[j].Base()
+	})
+
+	fset := token.NewFileSet()
+	for _, tf := range others {
+		fset.AddFile(tf.Name(), tf.Base(), tf.Size()).SetLines(tf.Lines())
+	}
+
+	src, err := parser.ParseFile(fset, f.Fset.Position(f.Src.Pos()).Filename, mutation, parser.ParseComments|parser.AllErrors)
 	if err != nil {
-		fmt.Println(err)
-		return nil, nil, nil, nil, fmt.Errorf("could not load package of file %q: %v", file, err)
+		return err
 	}
 
-	pkgInfo := prog[0]
+	files := make([]*ast.File, 0, len(f.Files))
+	for _, file := range f.Files {
+		if file == f.Src {
+			file = src
+		}
 
-	var src *ast.File
-	for _, f := range pkgInfo.Syntax {
-		if pkgInfo.Fset.Position(f.Pos()).Filename == fileAbs {
-			src = f
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
 
-			break
+			if _, ok := imports[path]; !ok {
+				return nil
+			}
 		}
+
+		files = append(files, file)
+	}
+
+	conf := types.Config{
+		GoVersion: f.GoVersion,
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			return imports[path], nil
+		}),
+		Sizes: f.Sizes,
 	}
 
-	return src, pkgInfo.Fset, pkgInfo.Types, pkgInfo.TypesInfo, nil
+	_, err = conf.Check(f.Pkg.Path(), fset, files, nil)
+
+	return err
+}
+
+// moduleGoVersion returns the Go version of the go directive of the module of the given directory, e.g. "go1.21", or an empty string if there is no module or no go directive.
+func moduleGoVersion(dir string) (string, error) {
+	for {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) >= 2 && fields[0] == "go" {
+					return "go" + fields[1], nil
+				}
+			}
+
+			return "", nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (i importerFunc) Import(path string) (*types.Package, error) {
+	return i(path)
 }
@@ -1,6 +1,9 @@
 package mutesting
 
 import (
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,3 +13,66 @@ func TestParseAndTypeCheckFileTypeCheckWholePackage(t *testing.T) {
 	_, _, _, _, err := ParseAndTypeCheckFile("astutil/create.go")
 	assert.Nil(t, err)
 }
+
+func TestParseAndTypeCheckFiles(t *testing.T) {
+	files, err := ParseAndTypeCheckFiles([]string{"astutil/query.go", "parse.go", "astutil/create.go"})
+	assert.Nil(t, err)
+
+	if assert.Len(t, files, 3) {
+		assert.Equal(t, "astutil/query.go", files[0].File)
+		assert.Equal(t, "parse.go", files[1].File)
+		assert.Equal(t, "astutil/create.go", files[2].File)
+
+		assert.Equal(t, "astutil", files[0].Src.Name.Name)
+		assert.Equal(t, "mutesting", files[1].Src.Name.Name)
+
+		// Every package is only loaded once and all files share the same file set
+		assert.True(t, files[0].Pkg == files[2].Pkg)
+		assert.True(t, files[0].Info == files[2].Info)
+		assert.True(t, files[0].Fset == files[1].Fset)
+	}
+}
+
+func TestTypeCheckMutation(t *testing.T) {
+	files, err := ParseAndTypeCheckFiles([]string{"astutil/create.go"})
+	assert.Nil(t, err)
+	f := files[0]
+
+	data, err := ioutil.ReadFile("astutil/create.go")
+	assert.Nil(t, err)
+
+	// The original file compiles
+	assert.Nil(t, TypeCheckMutation(f, data))
+
+	// An unused variable does not compile
+	mutation := strings.Replace(string(data), "func CreateNoopOfStatement(", "func unused() {\n\tv := 1\n}\n\nfunc CreateNoopOfStatement(", 1)
+	assert.NotEqual(t, string(data), mutation)
+	assert.NotNil(t, TypeCheckMutation(f, []byte(mutation)))
+
+	// Identifiers of other files of the package are known
+	mutation = strings.Replace(string(data), "func CreateNoopOfStatement(", "var _ = IdentifiersInStatement\n\nfunc CreateNoopOfStatement(", 1)
+	assert.Nil(t, TypeCheckMutation(f, []byte(mutation)))
+
+	// The Go version of the module is respected
+	assert.Equal(t, "go1.21", f.GoVersion)
+	mutation = strings.Replace(string(data), "func CreateNoopOfStatement(", "func rangeOverInt() {\n\tfor range 3 {\n\t}\n}\n\nfunc CreateNoopOfStatement(", 1)
+	assert.NotNil(t, TypeCheckMutation(f, []byte(mutation)))
+
+	// The sizes of the target platform are known
+	assert.NotNil(t, f.Sizes)
+
+	// The shared file set does not grow with every mutation
+	base := f.Fset.Base()
+	assert.Nil(t, TypeCheckMutation(f, data))
+	assert.Equal(t, base, f.Fset.Base())
+}
+
+func TestModuleGoVersion(t *testing.T) {
+	goVersion, err := moduleGoVersion("astutil")
+	assert.Nil(t, err)
+	assert.Equal(t, "go1.21", goVersion)
+
+	goVersion, err = moduleGoVersion(os.TempDir())
+	assert.Nil(t, err)
+	assert.Equal(t, "", goVersion)
+}
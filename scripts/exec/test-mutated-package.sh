@@ -1,8 +1,8 @@
 #!/bin/bash
 
 # This exec script implements
-# - the replacement of the original file with the mutation,
-# - the execution of all tests originating from the package of the mutated file,
+# - the replacement of the original file with the mutation (or the usage of the overlay of the mutation if MUTATE_OVERLAY is set),
+# - the execution of all tests originating from the package of the mutated file (or only the tests of MUTATE_TESTS if it is set),
 # - and the reporting if the mutation was killed.
 
 if [ -z ${MUTATE_CHANGED+x} ]; then echo "MUTATE_CHANGED is not set"; exit 1; fi
@@ -25,16 +25,24 @@ trap sig_handler SIGHUP SIGINT SIGTERM
 
 export GOMUTESTING_DIFF=$(diff -u $MUTATE_ORIGINAL $MUTATE_CHANGED)
 
-mv $MUTATE_ORIGINAL $MUTATE_ORIGINAL.tmp
-cp $MUTATE_CHANGED $MUTATE_ORIGINAL
+if [ -n "$MUTATE_OVERLAY" ]; then
+	GOMUTESTING_OVERLAY="-overlay $MUTATE_OVERLAY"
+else
+	mv $MUTATE_ORIGINAL $MUTATE_ORIGINAL.tmp
+	cp $MUTATE_CHANGED $MUTATE_ORIGINAL
+fi
 
 export MUTATE_TIMEOUT=${MUTATE_TIMEOUT:-10}
 
+if [ -n "$MUTATE_TESTS" ]; then
+	GOMUTESTING_RUN="-run $MUTATE_TESTS"
+fi
+
 if [ -n "$TEST_RECURSIVE" ]; then
 	TEST_RECURSIVE="/..."
 fi
 
-GOMUTESTING_TEST=$(go test -timeout $(printf '%ds' $MUTATE_TIMEOUT) $MUTATE_PACKAGE$TEST_RECURSIVE 2>&1)
+GOMUTESTING_TEST=$(go test -timeout $(printf '%ds' $MUTATE_TIMEOUT) $GOMUTESTING_OVERLAY $GOMUTESTING_RUN $MUTATE_PACKAGE$TEST_RECURSIVE 2>&1)
 export GOMUTESTING_RESULT=$?
 
 if [ "$MUTATE_DEBUG" = true ] ; then
@@ -35,10 +35,15 @@ func Mutator(t *testing.T, m mutator.Mutator, testFile string, count int) {
 	assert.Equal(t, count, n)
 
 	// Mutate all relevant nodes -> test whole mutation process
-	changed := mutesting.MutateWalk(pkg, info, src, m)
+	changed := mutesting.MutateWalkNodes(pkg, info, src, m)
 
 	for i := 0; i < count; i++ {
-		assert.True(t, <-changed)
+		// The changed node must be part of the original source code
+		node := <-changed
+		if assert.NotNil(t, node) {
+			assert.True(t, node.Pos.IsValid())
+			assert.True(t, node.End.IsValid())
+		}
 
 		buf := new(bytes.Buffer)
 		err = printer.Fprint(buf, fset, src)
@@ -53,9 +58,9 @@ func Mutator(t *testing.T, m mutator.Mutator, testFile string, count int) {
 			assert.Nil(t, err)
 		}
 
-		changed <- true
+		changed <- nil
 
-		assert.True(t, <-changed)
+		assert.Nil(t, <-changed)
 
 		buf = new(bytes.Buffer)
 		err = printer.Fprint(buf, fset, src)
@@ -63,9 +68,33 @@ func Mutator(t *testing.T, m mutator.Mutator, testFile string, count int) {
 
 		assert.Equal(t, string(data), buf.String())
 
-		changed <- true
+		changed <- nil
 	}
 
 	_, ok := <-changed
 	assert.False(t, ok)
 }
+
+// MutatorNodes tests the changed nodes of a mutator.
+// It mutates the given original file with the given mutator and validates the source code of the changed node of every mutation with the given nodes.
+func MutatorNodes(t *testing.T, m mutator.Mutator, testFile string, nodes []string) {
+	data, err := ioutil.ReadFile(testFile)
+	assert.Nil(t, err)
+
+	src, fset, pkg, info, err := mutesting.ParseAndTypeCheckFile(testFile, `-tags=test`)
+	assert.Nil(t, err)
+
+	var actual []string
+
+	changed := mutesting.MutateWalkNodes(pkg, info, src, m)
+
+	for node := range changed {
+		actual = append(actual, string(data[fset.Position(node.Pos).Offset:fset.Position(node.End).Offset]))
+
+		changed <- nil
+		<-changed
+		changed <- nil
+	}
+
+	assert.Equal(t, nodes, actual)
+}
@@ -0,0 +1,46 @@
+//go:build test
+// +build test
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrNotFound = errors.New("not found")
+var ErrClosed = errors.New("closed")
+
+func find(name string) error {
+	if name == "" {
+		return ErrNotFound
+	}
+
+	if name == "-" {
+		return io.ErrShortBuffer
+	}
+
+	return fmt.Errorf("cannot find %q: %w", name, ErrClosed)
+}
+
+func main() {
+	err := find("x")
+
+	if !errors.Is(err, ErrClosed) {
+		fmt.Println("100%w")
+	}
+
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		fmt.Println(pathErr.Path)
+	}
+}
+
+func shadowed() error {
+	ErrClosed := fmt.Errorf("local")
+	_ = ErrClosed
+
+	return ErrNotFound
+}
@@ -0,0 +1,35 @@
+//go:build test
+// +build test
+
+package main
+
+import (
+	"fmt"
+)
+
+func NewRange(lo int, hi int) []int {
+	return []int{lo, hi}
+}
+
+func scale(factor float64, n int, name string) float64 {
+	return factor * float64(n)
+}
+
+func main() {
+	dst := make([]int, 3)
+	src := []int{1, 2, 3}
+
+	copy(src, dst)
+
+	r := NewRange(1, 10)
+
+	lo, hi := 2, 4
+	r = NewRange(lo, hi)
+	r = NewRange(lo, lo)
+
+	fmt.Println(r, scale(1.5, 2, "double"))
+}
+
+func bounds(lo int, hi int) (int, int) {
+	return min(lo, hi), max(lo, hi)
+}
@@ -0,0 +1,38 @@
+//go:build test
+// +build test
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type Status int
+
+const StatusActive Status = 0
+const StatusPending Status = 1
+const StatusDeleted Status = 2
+const StatusDefault = StatusActive
+
+const limit = 10
+
+func next(s Status) Status {
+	switch s {
+	case StatusPending:
+		return StatusActive
+	}
+
+	return s
+}
+
+func main() {
+	fmt.Println(next(StatusPending), limit, time.Hour)
+}
+
+func shadowed() Status {
+	StatusDeleted := 3
+	_ = StatusDeleted
+
+	return StatusPending
+}
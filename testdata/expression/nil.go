@@ -0,0 +1,37 @@
+//go:build test
+// +build test
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+type handler func(string)
+
+func write(w io.Writer, data []byte, names map[string]int) {
+	if w == nil || data == nil {
+		return
+	}
+
+	_, _ = w.Write(data)
+	fmt.Println(len(names))
+}
+
+func main() {
+	var buf bytes.Buffer
+	var h handler = func(s string) {}
+	var p *bytes.Buffer
+
+	data := []byte("hello")
+	p = &buf
+	_ = p
+
+	write(p, data, nil)
+	write(&buf, append(data, '!'), map[string]int{})
+
+	h("done")
+	fmt.Println(p.Len())
+}
@@ -0,0 +1,27 @@
+//go:build test
+// +build test
+
+package main
+
+import (
+	"fmt"
+)
+
+var offset = 3
+
+func sum(i int, j int) int {
+	name := "sum"
+	k := i + j
+
+	fmt.Println(name)
+
+	return k
+}
+
+func main() {
+	a := 1
+	b := offset
+	s := "hello"
+
+	fmt.Println(sum(a, offset), s)
+}
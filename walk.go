@@ -3,6 +3,7 @@ package mutesting
 import (
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"strings"
 
@@ -87,6 +88,69 @@ func (w *mutateWalk) Visit(node ast.Node) ast.Visitor {
 	return w
 }
 
+// ChangedNode holds the node which is changed by a mutation together with its source range before the mutation was applied.
+type ChangedNode struct {
+	Node ast.Node
+	Pos  token.Pos
+	End  token.Pos
+}
+
+// MutateWalkNodes mutates the given node with the given mutator like MutateWalk but sends the changed node of the mutation for every mutated state and nil for every original state over the control channel.
+func MutateWalkNodes(pkg *types.Package, info *types.Info, node ast.Node, m mutator.Mutator) chan *ChangedNode {
+	w := &mutateNodesWalk{
+		changed: make(chan *ChangedNode),
+		mutator: m,
+		pkg:     pkg,
+		info:    info,
+	}
+
+	go func() {
+		ast.Walk(w, node)
+
+		close(w.changed)
+	}()
+
+	return w.changed
+}
+
+type mutateNodesWalk struct {
+	changed chan *ChangedNode
+	mutator mutator.Mutator
+	pkg     *types.Package
+	info    *types.Info
+}
+
+// Visit implements the Visit method of the ast.Visitor interface
+func (w *mutateNodesWalk) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return w
+	}
+
+	for _, m := range w.mutator(w.pkg, w.info, node) {
+		n := m.Node
+		if n == nil {
+			n = node
+		}
+
+		// The range must be determined before the change since the positions of a node might depend on its children
+		changed := &ChangedNode{
+			Node: n,
+			Pos:  n.Pos(),
+			End:  n.End(),
+		}
+
+		m.Change()
+		w.changed <- changed
+		<-w.changed
+
+		m.Reset()
+		w.changed <- nil
+		<-w.changed
+	}
+
+	return w
+}
+
 // PrintWalk traverses the AST of the given node and prints every node to STDOUT.
 func PrintWalk(node ast.Node) {
 	w := &printWalk{